@@ -0,0 +1,371 @@
+package archive
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression type of the archive
+type Compression string
+
+// Supported compression types
+const (
+	Gzip Compression = "gzip"
+	Zstd Compression = "zstd"
+)
+
+const manifestName = "manifest.json"
+const profilePrefix = "profile/"
+
+var gzipMagic = []byte{0x1f, 0x8b}
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// ErrUnsupportedFormat is returned when the compression type cannot be determined
+var ErrUnsupportedFormat = errors.New("unsupported archive format")
+
+// ErrNoManifest is returned by the NewReader() function when the archive does not start with the manifest
+var ErrNoManifest = errors.New("manifest not found")
+
+// ErrUnsafePath is returned by the Extract() method when the archive entry points outside of the destination
+var ErrUnsafePath = errors.New("unsafe path")
+
+// Manifest describes the profile archive
+type Manifest struct {
+	Version   string    `json:"manygram-version"`
+	Profile   string    `json:"profile"`
+	CreatedAt time.Time `json:"created-at"`
+	Flavor    string    `json:"flavor"`
+}
+
+// CompressionFromPath guesses the compression type by the archive file extension
+func CompressionFromPath(filePath string) (Compression, error) {
+	switch {
+	case strings.HasSuffix(filePath, ".tar.gz"), strings.HasSuffix(filePath, ".tgz"):
+		return Gzip, nil
+	case strings.HasSuffix(filePath, ".tar.zst"), strings.HasSuffix(filePath, ".tar.zstd"):
+		return Zstd, nil
+	}
+	return "", fmt.Errorf("%s: %w", filePath, ErrUnsupportedFormat)
+}
+
+// Write writes the compressed archive of the directory prefixed with the manifest,
+// paths relative to the directory for which skip returns true are not archived
+func Write(w io.Writer, compression Compression, dir string, manifest *Manifest, skip func(string) bool) error {
+	var cw io.WriteCloser
+	var err error
+	switch compression {
+	case Gzip:
+		cw = gzip.NewWriter(w)
+	case Zstd:
+		cw, err = zstd.NewWriter(w)
+		if err != nil {
+			return err
+		}
+	default:
+		return ErrUnsupportedFormat
+	}
+	tw := tar.NewWriter(cw)
+	if err := writeManifest(tw, manifest); err != nil {
+		return err
+	}
+	err = filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+		if skip != nil && skip(relPath) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return writeEntry(tw, filePath, profilePrefix+filepath.ToSlash(relPath), info)
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return cw.Close()
+}
+
+func writeManifest(tw *tar.Writer, manifest *Manifest) error {
+	bs, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     manifestName,
+		Mode:     0644,
+		Size:     int64(len(bs)),
+		ModTime:  manifest.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(bs)
+	return err
+}
+
+func writeEntry(tw *tar.Writer, filePath string, name string, info os.FileInfo) error {
+	var link string
+	mode := info.Mode()
+	switch {
+	case mode.IsDir():
+		name += "/"
+	case mode&os.ModeSymlink != 0:
+		target, err := os.Readlink(filePath)
+		if err != nil {
+			return err
+		}
+		link = target
+	case mode.IsRegular():
+	default:
+		// sockets, pipes and devices are not archived
+		return nil
+	}
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	// owner is not preserved because the archive is meant to be moved between machines
+	header.Uid, header.Gid, header.Uname, header.Gname = 0, 0, "", ""
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if !mode.IsRegular() {
+		return nil
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(tw, file)
+	return err
+}
+
+// Reader reads the profile archive
+type Reader struct {
+	Manifest *Manifest
+	closer   io.Closer
+	tr       *tar.Reader
+}
+
+// NewReader detects the compression type, opens the archive and reads the manifest
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	var cr io.Reader
+	var closer io.Closer
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		cr, closer = gr, gr
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		cr, closer = zr, zstdCloser{zr}
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	ar := &Reader{closer: closer, tr: tar.NewReader(cr)}
+	if err := ar.readManifest(); err != nil {
+		closer.Close()
+		return nil, err
+	}
+	return ar, nil
+}
+
+type zstdCloser struct {
+	*zstd.Decoder
+}
+
+func (c zstdCloser) Close() error {
+	c.Decoder.Close()
+	return nil
+}
+
+func (ar *Reader) readManifest() error {
+	header, err := ar.tr.Next()
+	if err == io.EOF {
+		return ErrNoManifest
+	}
+	if err != nil {
+		return err
+	}
+	if header.Name != manifestName {
+		return ErrNoManifest
+	}
+	manifest := new(Manifest)
+	if err := json.NewDecoder(ar.tr).Decode(manifest); err != nil {
+		return fmt.Errorf("malformed manifest: %w", err)
+	}
+	ar.Manifest = manifest
+	return nil
+}
+
+// Extract extracts the archived profile directory into the dst directory,
+// entries pointing outside of the destination are refused
+func (ar *Reader) Extract(dst string) error {
+	for {
+		header, err := ar.tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		relPath, err := entryPath(header.Name)
+		if err != nil {
+			return err
+		}
+		if err := checkParents(dst, relPath); err != nil {
+			return fmt.Errorf("%s: %w", header.Name, err)
+		}
+		filePath := filepath.Join(dst, filepath.FromSlash(relPath))
+		mode := os.FileMode(header.Mode).Perm()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(filePath, 0700); err != nil {
+				return err
+			}
+			if err := os.Chmod(filePath, mode|0700); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+				return err
+			}
+			if err := extractFile(ar.tr, filePath, mode); err != nil {
+				return err
+			}
+			if err := os.Chtimes(filePath, header.ModTime, header.ModTime); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if !isSafeLink(relPath, header.Linkname) {
+				return fmt.Errorf("%s -> %s: %w", header.Name, header.Linkname, ErrUnsafePath)
+			}
+			if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, filePath); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%s: unsupported entry type %q", header.Name, header.Typeflag)
+		}
+	}
+}
+
+// Close closes the decompressor
+func (ar *Reader) Close() error {
+	return ar.closer.Close()
+}
+
+func entryPath(name string) (string, error) {
+	if !strings.HasPrefix(name, profilePrefix) {
+		return "", fmt.Errorf("%s: %w", name, ErrUnsafePath)
+	}
+	relPath := strings.TrimPrefix(name, profilePrefix)
+	cleaned := path.Clean(relPath)
+	if relPath == "" || cleaned == "." || path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("%s: %w", name, ErrUnsafePath)
+	}
+	if cleaned != strings.TrimSuffix(relPath, "/") {
+		return "", fmt.Errorf("%s: %w", name, ErrUnsafePath)
+	}
+	return cleaned, nil
+}
+
+// checkParents refuses to write through symlinks extracted earlier
+func checkParents(dst string, relPath string) error {
+	parent := dst
+	parts := strings.Split(relPath, "/")
+	for _, part := range parts[:len(parts)-1] {
+		parent = filepath.Join(parent, part)
+		info, err := os.Lstat(parent)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return ErrUnsafePath
+		}
+	}
+	return nil
+}
+
+// isSafeLink checks that the symlink target stays inside the destination, ".." is only allowed
+// at the start of the target since it may follow a symlink extracted before or after the link
+// (e.g., "c/.." where c -> "." points outside while the cleaned path does not)
+func isSafeLink(relPath string, target string) bool {
+	if target == "" || path.IsAbs(target) {
+		return false
+	}
+	depth := 0
+	if dir := path.Dir(relPath); dir != "." {
+		depth = len(strings.Split(dir, "/"))
+	}
+	descended := false
+	for _, part := range strings.Split(target, "/") {
+		switch {
+		case part == "" || part == ".":
+		case part != "..":
+			descended = true
+		case descended || depth == 0:
+			return false
+		default:
+			depth--
+		}
+	}
+	return true
+}
+
+func extractFile(r io.Reader, filePath string, mode os.FileMode) error {
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Chmod(filePath, mode)
+}
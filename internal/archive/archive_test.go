@@ -0,0 +1,205 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestArchiveSuite struct {
+	suite.Suite
+	dir      string
+	srcDir   string
+	dstDir   string
+	manifest *Manifest
+}
+
+func (s *TestArchiveSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "test-archive-*")
+	s.Require().NoError(err)
+	s.dir = dir
+	s.srcDir = path.Join(dir, "src")
+	s.dstDir = path.Join(dir, "dst")
+	s.Require().NoError(os.Mkdir(s.dstDir, 0755))
+	s.manifest = &Manifest{
+		Version:   "0.2.0",
+		Profile:   "foo",
+		CreatedAt: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Flavor:    "native",
+	}
+}
+
+func (s *TestArchiveSuite) TearDownTest() {
+	err := os.RemoveAll(s.dir)
+	s.Require().NoError(err)
+}
+
+func (s *TestArchiveSuite) WriteFile(relPath string, perm os.FileMode) {
+	filePath := path.Join(s.srcDir, relPath)
+	s.Require().NoError(os.MkdirAll(path.Dir(filePath), 0755))
+	s.Require().NoError(ioutil.WriteFile(filePath, []byte(relPath), perm))
+	s.Require().NoError(os.Chmod(filePath, perm))
+}
+
+func (s *TestArchiveSuite) MakeSource() {
+	s.WriteFile("tdata/settingss", 0600)
+	s.WriteFile("tdata/user_data/cache", 0644)
+	s.WriteFile("run.sh", 0755)
+	s.Require().NoError(os.Symlink("tdata/settingss", path.Join(s.srcDir, "link")))
+}
+
+func (s *TestArchiveSuite) RoundTrip(compression Compression) {
+	s.MakeSource()
+	buf := new(bytes.Buffer)
+	err := Write(buf, compression, s.srcDir, s.manifest, func(relPath string) bool {
+		return relPath == "tdata/user_data"
+	})
+	s.Require().NoError(err)
+	ar, err := NewReader(buf)
+	s.Require().NoError(err)
+	defer ar.Close()
+	s.Require().Equal(s.manifest, ar.Manifest)
+	s.Require().NoError(ar.Extract(s.dstDir))
+	content, err := ioutil.ReadFile(path.Join(s.dstDir, "tdata/settingss"))
+	s.Require().NoError(err)
+	s.Require().Equal("tdata/settingss", string(content))
+	info, err := os.Stat(path.Join(s.dstDir, "tdata/settingss"))
+	s.Require().NoError(err)
+	s.Require().Equal(os.FileMode(0600), info.Mode().Perm())
+	info, err = os.Stat(path.Join(s.dstDir, "run.sh"))
+	s.Require().NoError(err)
+	s.Require().Equal(os.FileMode(0755), info.Mode().Perm())
+	target, err := os.Readlink(path.Join(s.dstDir, "link"))
+	s.Require().NoError(err)
+	s.Require().Equal("tdata/settingss", target)
+	s.Require().NoDirExists(path.Join(s.dstDir, "tdata/user_data"))
+}
+
+func (s *TestArchiveSuite) TestRoundTripGzip() {
+	s.RoundTrip(Gzip)
+}
+
+func (s *TestArchiveSuite) TestRoundTripZstd() {
+	s.RoundTrip(Zstd)
+}
+
+func (s *TestArchiveSuite) WriteRaw(withManifest bool, headers ...*tar.Header) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	if withManifest {
+		s.Require().NoError(writeManifest(tw, s.manifest))
+	}
+	for _, header := range headers {
+		s.Require().NoError(tw.WriteHeader(header))
+		if header.Size > 0 {
+			_, err := tw.Write([]byte(strings.Repeat("x", int(header.Size))))
+			s.Require().NoError(err)
+		}
+	}
+	s.Require().NoError(tw.Close())
+	s.Require().NoError(gw.Close())
+	return buf
+}
+
+func (s *TestArchiveSuite) TestErrNoManifest() {
+	buf := s.WriteRaw(false, &tar.Header{Typeflag: tar.TypeReg, Name: "profile/file", Mode: 0644, Size: 1})
+	ar, err := NewReader(buf)
+	s.Require().True(errors.Is(err, ErrNoManifest), err)
+	s.Require().Nil(ar)
+}
+
+func (s *TestArchiveSuite) TestErrUnsupportedFormat() {
+	ar, err := NewReader(strings.NewReader("plain text"))
+	s.Require().True(errors.Is(err, ErrUnsupportedFormat), err)
+	s.Require().Nil(ar)
+}
+
+func (s *TestArchiveSuite) AssertUnsafe(headers ...*tar.Header) {
+	ar, err := NewReader(s.WriteRaw(true, headers...))
+	s.Require().NoError(err)
+	defer ar.Close()
+	err = ar.Extract(s.dstDir)
+	s.Require().True(errors.Is(err, ErrUnsafePath), err)
+	s.Require().NoFileExists(path.Join(s.dir, "evil"))
+}
+
+func (s *TestArchiveSuite) TestErrUnsafePathTraversal() {
+	s.AssertUnsafe(&tar.Header{Typeflag: tar.TypeReg, Name: "profile/../evil", Mode: 0644, Size: 1})
+}
+
+func (s *TestArchiveSuite) TestErrUnsafePathAbsolute() {
+	s.AssertUnsafe(&tar.Header{Typeflag: tar.TypeReg, Name: "/evil", Mode: 0644, Size: 1})
+}
+
+func (s *TestArchiveSuite) TestErrUnsafePathOutsideProfile() {
+	s.AssertUnsafe(&tar.Header{Typeflag: tar.TypeReg, Name: "evil", Mode: 0644, Size: 1})
+}
+
+func (s *TestArchiveSuite) TestErrUnsafeSymlinkTarget() {
+	s.AssertUnsafe(&tar.Header{Typeflag: tar.TypeSymlink, Name: "profile/link", Linkname: "../evil"})
+}
+
+func (s *TestArchiveSuite) TestErrUnsafeWriteThroughSymlink() {
+	s.AssertUnsafe(
+		&tar.Header{Typeflag: tar.TypeSymlink, Name: "profile/link", Linkname: "."},
+		&tar.Header{Typeflag: tar.TypeSymlink, Name: "profile/link/link2", Linkname: "../evil"},
+	)
+}
+
+func (s *TestArchiveSuite) TestErrUnsafeSymlinkThroughSymlink() {
+	s.AssertUnsafe(
+		&tar.Header{Typeflag: tar.TypeSymlink, Name: "profile/c", Linkname: "."},
+		&tar.Header{Typeflag: tar.TypeSymlink, Name: "profile/a", Linkname: "c/.."},
+	)
+}
+
+func (s *TestArchiveSuite) TestErrUnsafeSymlinkThroughLaterSymlink() {
+	s.AssertUnsafe(
+		&tar.Header{Typeflag: tar.TypeSymlink, Name: "profile/a", Linkname: "sub/c/../.."},
+		&tar.Header{Typeflag: tar.TypeDir, Name: "profile/sub/", Mode: 0755},
+		&tar.Header{Typeflag: tar.TypeSymlink, Name: "profile/sub/c", Linkname: ".."},
+	)
+}
+
+func (s *TestArchiveSuite) TestSafeSymlinks() {
+	ar, err := NewReader(s.WriteRaw(true,
+		&tar.Header{Typeflag: tar.TypeDir, Name: "profile/sub/dir/", Mode: 0755},
+		&tar.Header{Typeflag: tar.TypeSymlink, Name: "profile/sub/dir/up", Linkname: "../../tdata/./settings"},
+		&tar.Header{Typeflag: tar.TypeSymlink, Name: "profile/down", Linkname: "sub/dir"},
+	))
+	s.Require().NoError(err)
+	defer ar.Close()
+	s.Require().NoError(ar.Extract(s.dstDir))
+	target, err := os.Readlink(path.Join(s.dstDir, "down"))
+	s.Require().NoError(err)
+	s.Require().Equal("sub/dir", target)
+}
+
+func (s *TestArchiveSuite) TestCompressionFromPath() {
+	for filePath, expected := range map[string]Compression{
+		"foo.tar.gz":   Gzip,
+		"foo.tgz":      Gzip,
+		"foo.tar.zst":  Zstd,
+		"foo.tar.zstd": Zstd,
+	} {
+		compression, err := CompressionFromPath(filePath)
+		s.Require().NoError(err)
+		s.Require().Equal(expected, compression)
+	}
+	_, err := CompressionFromPath("foo.zip")
+	s.Require().True(errors.Is(err, ErrUnsupportedFormat), err)
+}
+
+func TestArchiveSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestArchiveSuite))
+}
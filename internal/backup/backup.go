@@ -0,0 +1,128 @@
+package backup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/un-def/manygram/internal/crypt"
+)
+
+// Extension of backup files, backups are compressed with zstd
+const Extension = ".tar.zst"
+
+// EncryptedExtension of backup files encrypted with a passphrase
+const EncryptedExtension = Extension + crypt.Extension
+
+const timeLayout = "20060102T150405Z"
+
+// DefaultPolicy provides values of the retention parameters that are not configured
+var DefaultPolicy = Policy{KeepLast: 3, KeepDaily: 7, KeepWeekly: 4}
+
+// Backup is a snapshot of the profile stored in the backup directory
+type Backup struct {
+	Profile   string
+	Path      string
+	Time      time.Time
+	Encrypted bool
+}
+
+// ProfileDir returns the path to the directory containing backups of the profile
+func ProfileDir(dir string, profileName string) string {
+	return path.Join(dir, profileName)
+}
+
+// Path returns the path to the backup of the profile taken at the time
+func Path(dir string, profileName string, t time.Time, encrypted bool) string {
+	ext := Extension
+	if encrypted {
+		ext = EncryptedExtension
+	}
+	return path.Join(ProfileDir(dir, profileName), profileName+"-"+t.UTC().Format(timeLayout)+ext)
+}
+
+// List returns backups of the profile sorted from newest to oldest,
+// files not matching the backup name format are skipped
+func List(dir string, profileName string) ([]*Backup, error) {
+	profileDir := ProfileDir(dir, profileName)
+	infos, err := ioutil.ReadDir(profileDir)
+	if err != nil {
+		return nil, err
+	}
+	prefix := profileName + "-"
+	var backups []*Backup
+	for _, info := range infos {
+		name := info.Name()
+		encrypted := strings.HasSuffix(name, EncryptedExtension)
+		stem := strings.TrimSuffix(name, crypt.Extension)
+		if !info.Mode().IsRegular() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(stem, Extension) {
+			continue
+		}
+		t, err := time.Parse(timeLayout, strings.TrimSuffix(strings.TrimPrefix(stem, prefix), Extension))
+		if err != nil {
+			continue
+		}
+		backups = append(backups, &Backup{profileName, path.Join(profileDir, name), t, encrypted})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+	return backups, nil
+}
+
+// Policy is the retention policy of backups,
+// a backup is kept if any of the rules selects it
+type Policy struct {
+	// KeepLast is the number of the most recent backups to keep
+	KeepLast int
+	// KeepDaily is the number of the most recent days to keep the last backup of
+	KeepDaily int
+	// KeepWeekly is the number of the most recent weeks to keep the last backup of
+	KeepWeekly int
+}
+
+// Apply splits backups sorted from newest to oldest into kept and expired ones,
+// days and weeks are determined in the local time zone
+func (p *Policy) Apply(backups []*Backup) ([]*Backup, []*Backup) {
+	keep := make(map[*Backup]bool, len(backups))
+	for idx, b := range backups {
+		if idx >= p.KeepLast {
+			break
+		}
+		keep[b] = true
+	}
+	keepBuckets(backups, keep, p.KeepDaily, func(t time.Time) string {
+		return t.Local().Format("2006-01-02")
+	})
+	keepBuckets(backups, keep, p.KeepWeekly, func(t time.Time) string {
+		year, week := t.Local().ISOWeek()
+		return fmt.Sprintf("%d-%02d", year, week)
+	})
+	var kept, expired []*Backup
+	for _, b := range backups {
+		if keep[b] {
+			kept = append(kept, b)
+		} else {
+			expired = append(expired, b)
+		}
+	}
+	return kept, expired
+}
+
+// keepBuckets keeps the newest backup of each of the count most recent buckets
+func keepBuckets(backups []*Backup, keep map[*Backup]bool, count int, bucket func(time.Time) string) {
+	last := ""
+	for _, b := range backups {
+		if count <= 0 {
+			return
+		}
+		if current := bucket(b.Time); current != last {
+			keep[b] = true
+			last = current
+			count--
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package backup
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestBackupSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *TestBackupSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "test-backup-*")
+	s.Require().NoError(err)
+	s.dir = dir
+}
+
+func (s *TestBackupSuite) TearDownTest() {
+	s.Require().NoError(os.RemoveAll(s.dir))
+}
+
+func (s *TestBackupSuite) TestPath() {
+	t := time.Date(2024, 3, 5, 7, 8, 9, 0, time.UTC)
+	s.Require().Equal(path.Join(s.dir, "foo", "foo-20240305T070809Z.tar.zst"), Path(s.dir, "foo", t, false))
+	s.Require().Equal(path.Join(s.dir, "foo", "foo-20240305T070809Z.tar.zst.enc"), Path(s.dir, "foo", t, true))
+}
+
+func (s *TestBackupSuite) TestList() {
+	older := time.Date(2024, 3, 5, 7, 8, 9, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	newest := newer.Add(time.Hour)
+	s.Require().NoError(os.MkdirAll(ProfileDir(s.dir, "foo"), 0755))
+	for _, name := range []string{
+		path.Base(Path(s.dir, "foo", older, false)),
+		path.Base(Path(s.dir, "foo", newer, false)),
+		path.Base(Path(s.dir, "foo", newest, true)),
+		"foo-invalid.tar.zst",
+		"foo-20240305T070809Z.enc",
+		"foo_bar-20240305T070809Z.tar.zst",
+		"foo-20240305T070809Z.tar.gz",
+	} {
+		s.Require().NoError(ioutil.WriteFile(path.Join(ProfileDir(s.dir, "foo"), name), nil, 0644))
+	}
+	backups, err := List(s.dir, "foo")
+	s.Require().NoError(err)
+	s.Require().Equal([]*Backup{
+		{"foo", Path(s.dir, "foo", newest, true), newest, true},
+		{"foo", Path(s.dir, "foo", newer, false), newer, false},
+		{"foo", Path(s.dir, "foo", older, false), older, false},
+	}, backups)
+}
+
+func (s *TestBackupSuite) TestListErrNotExist() {
+	_, err := List(s.dir, "foo")
+	s.Require().True(errors.Is(err, os.ErrNotExist), err)
+}
+
+func TestBackupSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestBackupSuite))
+}
+
+type TestPolicySuite struct {
+	suite.Suite
+}
+
+// Backups returns backups taken at the specified hours before now sorted from newest to oldest
+func (s *TestPolicySuite) Backups(start time.Time, hours ...int) []*Backup {
+	backups := make([]*Backup, len(hours))
+	for idx, hour := range hours {
+		backups[idx] = &Backup{Profile: "foo", Time: start.Add(-time.Duration(hour) * time.Hour)}
+	}
+	return backups
+}
+
+func (s *TestPolicySuite) TestKeepLast() {
+	backups := s.Backups(time.Now(), 0, 1, 2, 3)
+	kept, expired := (&Policy{KeepLast: 2}).Apply(backups)
+	s.Require().Equal(backups[:2], kept)
+	s.Require().Equal(backups[2:], expired)
+}
+
+func (s *TestPolicySuite) TestKeepDaily() {
+	start := time.Date(2024, 3, 5, 12, 0, 0, 0, time.Local)
+	// two backups per day during four days
+	backups := s.Backups(start, 0, 1, 24, 25, 48, 49, 72, 73)
+	kept, expired := (&Policy{KeepDaily: 3}).Apply(backups)
+	s.Require().Equal([]*Backup{backups[0], backups[2], backups[4]}, kept)
+	s.Require().Len(expired, 5)
+}
+
+func (s *TestPolicySuite) TestKeepWeekly() {
+	// Tuesday
+	start := time.Date(2024, 3, 5, 12, 0, 0, 0, time.Local)
+	backups := s.Backups(start, 0, 24, 48, 7*24, 14*24, 21*24)
+	kept, _ := (&Policy{KeepLast: 1, KeepWeekly: 3}).Apply(backups)
+	// the Sunday backup and the backup a week ago belong to the previous ISO week
+	s.Require().Equal([]*Backup{backups[0], backups[2], backups[4]}, kept)
+}
+
+func (s *TestPolicySuite) TestKeepNothing() {
+	backups := s.Backups(time.Now(), 0, 1)
+	kept, expired := (&Policy{}).Apply(backups)
+	s.Require().Empty(kept)
+	s.Require().Equal(backups, expired)
+}
+
+func TestPolicySuiteTest(t *testing.T) {
+	suite.Run(t, new(TestPolicySuite))
+}
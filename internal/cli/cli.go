@@ -9,7 +9,13 @@ import (
 const manygramVersion = "0.2.0"
 
 var parserFlags flags.Options = flags.HelpFlag | flags.PassDoubleDash
-var parser = flags.NewParser(nil, parserFlags)
+var parser = flags.NewParser(&options, parserFlags)
+
+var options struct {
+	Output string `long:"output" choice:"text" choice:"json" choice:"tsv" default:"text" description:"Output format of read-only commands"`
+	Yes    bool   `short:"y" long:"yes" description:"Do not ask for confirmation"`
+	DryRun bool   `long:"dry-run" description:"Only print filesystem and process operations of the command"`
+}
 
 func commandHandler(command flags.Commander, args []string) error {
 	if command == nil {
@@ -25,6 +31,14 @@ type profileOption struct {
 	} `positional-args:"true" required:"false"`
 }
 
+// optionalProfileOption is used by commands accepting either a profile name or an option
+// selecting several profiles at once (e.g. --all)
+type optionalProfileOption struct {
+	Profile struct {
+		Name string `description:"Profile name" positional-arg-name:"PROFILE"`
+	} `positional-args:"true"`
+}
+
 // Run command line interface
 func Run(args []string) *Error {
 	parser.SubcommandsOptional = true
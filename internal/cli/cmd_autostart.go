@@ -0,0 +1,14 @@
+package cli
+
+import "github.com/jessevdk/go-flags"
+
+var autostartCommand *flags.Command
+
+func init() {
+	autostartCommand, _ = parser.AddCommand(
+		"autostart", "Autostart subcommands", "Autostart subcommands.",
+		new(autostartCmd),
+	)
+}
+
+type autostartCmd struct{}
@@ -0,0 +1,48 @@
+package cli
+
+import "github.com/un-def/manygram/internal/desktop"
+
+func init() {
+	autostartCommand.AddCommand(
+		"disable", "Do not start the profile at login", "Remove the autostart entry of the profile.",
+		new(autostartDisableCmd),
+	)
+}
+
+type autostartDisableCmd struct {
+	profileOption
+}
+
+func (c *autostartDisableCmd) Execute(args []string) error {
+	profileName := c.Profile.Name
+	if options.DryRun {
+		dir := getAutostartDir()
+		exist, err := desktop.AutostartExist(dir, profileName)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return newError("Autostart for profile '%s' is not enabled.", profileName)
+		}
+		dryRun("remove autostart entry " + desktop.AutostartPath(dir, profileName))
+		return nil
+	}
+	removed, err := removeAutostartEntry(profileName)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return newError("Autostart for profile '%s' is not enabled.", profileName)
+	}
+	inUse, err := isProfileIconInUse(profileName)
+	if err != nil {
+		return err
+	}
+	if !inUse {
+		if err := removeProfileIcon(profileName); err != nil {
+			return err
+		}
+	}
+	printMessage("Autostart for profile '%s' has been disabled.", profileName)
+	return nil
+}
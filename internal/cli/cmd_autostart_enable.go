@@ -0,0 +1,33 @@
+package cli
+
+import "github.com/un-def/manygram/internal/desktop"
+
+func init() {
+	autostartCommand.AddCommand("enable", "Start the profile at login", `
+		Start Telegram Desktop with the profile minimized to tray at login.
+		The entry is written to $XDG_CONFIG_HOME/autostart.
+	`, new(autostartEnableCmd))
+}
+
+type autostartEnableCmd struct {
+	profileOption
+}
+
+func (c *autostartEnableCmd) Execute(args []string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	prof, err := readProfile(conf.ProfileDir, c.Profile.Name)
+	if err != nil {
+		return err
+	}
+	if dryRun("write autostart entry " + desktop.AutostartPath(getAutostartDir(), prof.Name)) {
+		return nil
+	}
+	if err := writeAutostartEntry(conf, prof); err != nil {
+		return err
+	}
+	printMessage("Autostart for profile '%s' has been enabled.", prof.Name)
+	return nil
+}
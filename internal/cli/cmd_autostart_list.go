@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/un-def/manygram/internal/desktop"
+	"github.com/un-def/manygram/internal/profile"
+)
+
+func init() {
+	autostartCommand.AddCommand(
+		"list", "List profiles started at login", "List profiles started at login.",
+		new(autostartListCmd),
+	)
+}
+
+type autostartListCmd struct{}
+
+type autostartStatus struct {
+	Name          string
+	Path          string
+	ProfileExists bool
+}
+
+var autostartStatusKeys = []string{"name", "path", "profile_exists"}
+
+func (s *autostartStatus) record() record {
+	return record{
+		{"name", s.Name},
+		{"path", s.Path},
+		{"profile_exists", s.ProfileExists},
+	}
+}
+
+func (c *autostartListCmd) Execute(args []string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	dir := getAutostartDir()
+	names, err := desktop.ListAutostart(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return newError("Failed to list autostart entries in %s.", dir, err)
+	}
+	var statuses []*autostartStatus
+	for _, name := range names {
+		exist, err := profile.IsProfileDirExist(profile.Path(conf.ProfileDir, name))
+		if err != nil {
+			return newError("Failed to check profile '%s'.", name, err)
+		}
+		statuses = append(statuses, &autostartStatus{name, desktop.AutostartPath(dir, name), exist})
+	}
+	if !isTextOutput() {
+		records := make([]record, len(statuses))
+		for idx, status := range statuses {
+			records[idx] = status.record()
+		}
+		return printRecords(autostartStatusKeys, records)
+	}
+	if len(statuses) == 0 {
+		printMessage("No profiles are started at login.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPROFILE EXISTS\tPATH")
+	for _, status := range statuses {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", status.Name, formatBool(status.ProfileExists), status.Path)
+	}
+	return w.Flush()
+}
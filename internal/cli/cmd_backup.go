@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/un-def/manygram/internal/archive"
+	"github.com/un-def/manygram/internal/backup"
+	"github.com/un-def/manygram/internal/config"
+	"github.com/un-def/manygram/internal/profile"
+	"github.com/un-def/manygram/internal/util"
+)
+
+var backupCommand *flags.Command
+
+func init() {
+	backupCommand, _ = parser.AddCommand("backup", "Back up profiles", `
+		Write a timestamped zstd-compressed snapshot of the profile into the backup directory
+		($XDG_DATA_HOME/manygram/backups by default, see 'backup-dir' config parameter).
+		Media caches and logs are not backed up. Expired backups are removed according to
+		'backup-keep-last', 'backup-keep-daily' and 'backup-keep-weekly' config parameters.
+		The running profile is not backed up unless it is closed within --wait duration.
+		Backups are encrypted with a passphrase if --encrypt is specified or 'backup-encrypt'
+		config parameter is set, the passphrase is read from --passphrase-file,
+		$MANYGRAM_PASSPHRASE, 'passphrase-file' config parameter or asked interactively.
+	`, new(backupCmd))
+	backupCommand.SubcommandsOptional = true
+}
+
+// backupCmd takes the profile name from the remaining arguments,
+// positional arguments would take precedence over the subcommands
+type backupCmd struct {
+	passphraseOption
+	All     bool          `short:"a" long:"all" description:"Back up all profiles"`
+	Wait    time.Duration `short:"w" long:"wait" description:"Wait for the running profile to be closed up to the duration" value-name:"DURATION"`
+	Encrypt bool          `short:"e" long:"encrypt" description:"Encrypt backups with a passphrase"`
+}
+
+func (c *backupCmd) Usage() string {
+	return "[backup-OPTIONS] [PROFILE]"
+}
+
+func (c *backupCmd) Execute(args []string) error {
+	if len(args) > 1 {
+		return newError("Too many arguments. Specify a single profile name or --all.")
+	}
+	profileName := ""
+	if len(args) == 1 {
+		profileName = args[0]
+	}
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	profiles, err := readProfiles(conf.ProfileDir, profileName, c.All)
+	if err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		return newError("There are no profiles. Use `manygram create PROFILE` to create a new one.")
+	}
+	// the passphrase is read once for all profiles
+	var passphrase []byte
+	if (c.Encrypt || conf.BackupEncrypt) && !options.DryRun {
+		if passphrase, err = c.readPassphrase(conf, true); err != nil {
+			return err
+		}
+	}
+	var failed int
+	for _, prof := range profiles {
+		if err := c.backupProfile(conf, prof, passphrase); err != nil {
+			if !c.All {
+				return err
+			}
+			printMessage("%s", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return newError("Failed to back up %d profile(s).", failed)
+	}
+	return nil
+}
+
+func (c *backupCmd) backupProfile(conf *config.Config, prof *profile.Profile, passphrase []byte) error {
+	if err := waitNotRunning(prof, c.Wait); err != nil {
+		return err
+	}
+	dir := getBackupDir(conf)
+	backups, err := backup.List(dir, prof.Name)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return newError("Failed to list backups of profile '%s'.", prof.Name, err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	encrypt := c.Encrypt || conf.BackupEncrypt
+	backupPath := backup.Path(dir, prof.Name, now, encrypt)
+	exist, err := util.Exist(backupPath)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return newError("Backup %s already exists.", backupPath)
+	}
+	// the new backup is taken into account by the retention policy
+	backups = append([]*backup.Backup{{Profile: prof.Name, Path: backupPath, Time: now, Encrypted: encrypt}}, backups...)
+	_, expired := getBackupPolicy(conf).Apply(backups)
+	operations := []string{"write backup " + backupPath}
+	if encrypt {
+		operations[0] = "write encrypted backup " + backupPath
+	}
+	for _, b := range expired {
+		operations = append(operations, "remove expired backup "+b.Path)
+	}
+	if dryRun(operations...) {
+		return nil
+	}
+	if err := os.MkdirAll(backup.ProfileDir(dir, prof.Name), 0700); err != nil {
+		return newError("Failed to create backup directory %s.", backup.ProfileDir(dir, prof.Name), err)
+	}
+	manifest := &archive.Manifest{
+		Version:   manygramVersion,
+		Profile:   prof.Name,
+		CreatedAt: now,
+		Flavor:    detectFlavor(conf),
+	}
+	opts := &profile.CopyOptions{SkipCache: true}
+	if err := writeArchive(backupPath, archive.Zstd, prof.Path, manifest, opts.Skip, passphrase); err != nil {
+		return newError("Failed to back up profile '%s'.", prof.Name, err)
+	}
+	printMessage("Profile '%s' has been backed up to %s.", prof.Name, backupPath)
+	for _, b := range expired {
+		if err := os.Remove(b.Path); err != nil {
+			return newError("Failed to remove expired backup %s.", b.Path, err)
+		}
+		printMessage("Expired backup %s has been removed.", b.Path)
+	}
+	return nil
+}
+
+// waitNotRunning waits for the profile to be closed up to the timeout
+func waitNotRunning(prof *profile.Profile, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		running, err := prof.IsRunning()
+		if err != nil {
+			return newError("Failed to check whether profile '%s' is running.", prof.Name, err)
+		}
+		if !running {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			if timeout == 0 {
+				return newError("Profile '%s' is running. Close Telegram Desktop first or use --wait.", prof.Name)
+			}
+			return newError("Profile '%s' is still running after %s.", prof.Name, timeout)
+		}
+		time.Sleep(time.Second)
+	}
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/un-def/manygram/internal/backup"
+	"github.com/un-def/manygram/internal/profile"
+)
+
+func init() {
+	backupCommand.AddCommand(
+		"list", "List backups", "List backups of the profile or of all profiles.",
+		new(backupListCmd),
+	)
+}
+
+type backupListCmd struct {
+	optionalProfileOption
+}
+
+type backupStatus struct {
+	Profile   string
+	CreatedAt time.Time
+	Size      int64
+	Encrypted bool
+	Path      string
+}
+
+var backupStatusKeys = []string{"profile", "created_at", "size", "encrypted", "path"}
+
+func (s *backupStatus) record() record {
+	return record{
+		{"profile", s.Profile},
+		{"created_at", s.CreatedAt.Format(time.RFC3339)},
+		{"size", s.Size},
+		{"encrypted", s.Encrypted},
+		{"path", s.Path},
+	}
+}
+
+func (c *backupListCmd) Execute(args []string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	dir := getBackupDir(conf)
+	names := []string{c.Profile.Name}
+	if c.Profile.Name == "" {
+		if names, err = listBackupProfiles(dir); err != nil {
+			return newError("Failed to list backups in %s.", dir, err)
+		}
+	} else if !profile.IsValidName(c.Profile.Name) {
+		return profileNameError(c.Profile.Name)
+	}
+	var statuses []*backupStatus
+	for _, name := range names {
+		backups, err := backup.List(dir, name)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return newError("Failed to list backups of profile '%s'.", name, err)
+		}
+		for _, b := range backups {
+			info, err := os.Stat(b.Path)
+			if err != nil {
+				return newError("Failed to read backup %s.", b.Path, err)
+			}
+			statuses = append(statuses, &backupStatus{name, b.Time.Local(), info.Size(), b.Encrypted, b.Path})
+		}
+	}
+	if !isTextOutput() {
+		records := make([]record, len(statuses))
+		for idx, status := range statuses {
+			records[idx] = status.record()
+		}
+		return printRecords(backupStatusKeys, records)
+	}
+	if len(statuses) == 0 {
+		printMessage("No backups found.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROFILE\tCREATED\tSIZE\tENCRYPTED\tPATH")
+	for _, status := range statuses {
+		fmt.Fprintf(
+			w, "%s\t%s\t%s\t%s\t%s\n",
+			status.Profile, status.CreatedAt.Format("2006-01-02 15:04:05"), formatSize(status.Size),
+			formatBool(status.Encrypted), status.Path,
+		)
+	}
+	return w.Flush()
+}
+
+// listBackupProfiles returns names of profiles having a directory in the backup directory
+func listBackupProfiles(dir string) ([]string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, info := range infos {
+		if info.IsDir() && profile.IsValidName(info.Name()) {
+			names = append(names, info.Name())
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/un-def/manygram/internal/backup"
+	"github.com/un-def/manygram/internal/profile"
+)
+
+func init() {
+	backupCommand.AddCommand("restore", "Restore the profile from a backup", `
+		Restore the profile from the latest backup or from the backup specified with --backup.
+		Use --name to restore the backup as a new profile or --force to replace
+		the existing profile, the replaced profile is moved to the trash
+		after the backup has been extracted.
+		The passphrase of the encrypted backup is read from --passphrase-file,
+		$MANYGRAM_PASSPHRASE, 'passphrase-file' config parameter or asked interactively.
+	`, new(backupRestoreCmd))
+}
+
+type backupRestoreCmd struct {
+	profileOption
+	passphraseOption
+	Backup string `short:"b" long:"backup" description:"Backup path (default: the latest backup of the profile)" value-name:"FILE"`
+	Name   string `short:"n" long:"name" description:"New profile name" value-name:"NAME"`
+	Force  bool   `short:"f" long:"force" description:"Replace the existing profile"`
+}
+
+func (c *backupRestoreCmd) Execute(args []string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	sourceName := c.Profile.Name
+	if !profile.IsValidName(sourceName) {
+		return profileNameError(sourceName)
+	}
+	profileName := c.Name
+	if profileName == "" {
+		profileName = sourceName
+	}
+	backupPath := c.Backup
+	if backupPath == "" {
+		backups, err := backup.List(getBackupDir(conf), sourceName)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return newError("Failed to list backups of profile '%s'.", sourceName, err)
+		}
+		if len(backups) == 0 {
+			return newError("There are no backups of profile '%s'. Use `manygram backup %[1]s` to create one.", sourceName)
+		}
+		backupPath = backups[0].Path
+	}
+	file, err := os.Open(backupPath)
+	if err != nil {
+		return newError("Failed to open backup %s.", backupPath, err)
+	}
+	defer file.Close()
+	ar, err := c.newArchiveReader(conf, file)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+	printMessage(
+		"Backup of profile '%s' created at %s.",
+		ar.Manifest.Profile, ar.Manifest.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+	)
+	replace := false
+	if err := profile.CheckNew(conf.ProfileDir, profileName); errors.Is(err, profile.ErrAlreadyExists) && c.Force {
+		replace = true
+	} else if errors.Is(err, profile.ErrAlreadyExists) {
+		return newError(
+			"Profile '%s' already exists. Use --force to replace it or --name to restore the backup as a new profile.",
+			profileName,
+		)
+	} else if err != nil {
+		return newImportError(profileName, err)
+	}
+	profilePath := profile.Path(conf.ProfileDir, profileName)
+	operations := []string{fmt.Sprintf("extract backup %s to profile directory %s", backupPath, profilePath)}
+	if replace {
+		prof, err := readProfile(conf.ProfileDir, profileName)
+		if err != nil {
+			return err
+		}
+		if err := checkNotRunning(prof); err != nil {
+			return err
+		}
+		trashDir, err := getTrashDir(profilePath)
+		if err != nil {
+			return err
+		}
+		operations = []string{
+			fmt.Sprintf("extract backup %s to a temporary directory in %s", backupPath, conf.ProfileDir),
+			fmt.Sprintf("move profile directory %s to the trash %s", profilePath, trashDir),
+			"rename the temporary directory to " + profilePath,
+		}
+		if ok, err := confirmAction(fmt.Sprintf("Replace profile '%s'?", profileName), operations); !ok {
+			return err
+		}
+		// the backup is extracted before the profile is replaced
+		// to keep the profile intact if the backup turns out to be broken
+		tmpDir, err := extractProfile(ar, conf.ProfileDir)
+		if err != nil {
+			return newError("Failed to restore profile '%s'.", profileName, err)
+		}
+		if err := profile.Trash(conf.ProfileDir, profileName, false, trashDir); err != nil {
+			os.RemoveAll(tmpDir)
+			return newError("Failed to move profile '%s' to the trash.", profileName, err)
+		}
+		printMessage("Profile '%s' has been moved to the trash.", profileName)
+		if err := os.Rename(tmpDir, profilePath); err != nil {
+			return newError(
+				"Failed to restore profile '%s'. The restored profile directory is left at %s.",
+				profileName, tmpDir, err,
+			)
+		}
+	} else if dryRun(withCombinedUpdate(operations)...) {
+		return nil
+	} else if err := importProfile(ar, conf.ProfileDir, profileName); err != nil {
+		return newImportError(profileName, err)
+	}
+	printMessage("Profile '%s' has been restored from %s.", profileName, backupPath)
+	prof, err := readProfile(conf.ProfileDir, profileName)
+	if err != nil {
+		return err
+	}
+	profConf, err := readProfileConfig(prof)
+	if err != nil {
+		return err
+	}
+	if profConf.DesktopEntry {
+		if err := writeDesktopEntry(conf, prof); err != nil {
+			return err
+		}
+		printMessage("Desktop entry for profile has been written.")
+	}
+	return updateCombinedDesktopEntry(conf)
+}
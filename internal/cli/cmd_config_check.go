@@ -1,10 +1,16 @@
 package cli
 
 import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 
+	"github.com/un-def/manygram/internal/desktop"
 	"github.com/un-def/manygram/internal/profile"
 	"github.com/un-def/manygram/internal/tg"
+	"github.com/un-def/manygram/internal/util"
 )
 
 func init() {
@@ -38,11 +44,82 @@ func (c *configCheckCmd) Execute(args []string) error {
 	if err != nil {
 		return newError("Check error: `profile-dir`", err)
 	}
-	profile.IsProfileDirExist(conf.ProfileDir)
 	printMessage("Profile directory: %s", conf.ProfileDir)
 	if !profileDirExist {
 		printMessage("Profile directory does not exist.")
 	}
+	brokenEntries, err := findBrokenDesktopEntries()
+	if err != nil {
+		return newError("Check error: desktop entries", err)
+	}
+	for _, entryPath := range brokenEntries {
+		printMessage(
+			"Warning: desktop entry %s points at a missing executable. Run `manygram desktop sync` to update it.",
+			entryPath,
+		)
+	}
 	printMessage("OK. Check passed.")
+	if !isTextOutput() {
+		return printRecord(record{
+			{"config", getConfigPath()},
+			{"exec_path", telegram.Path},
+			{"exec_full_path", telegram.FullPath},
+			{"exec_real_path", telegram.RealPath},
+			{"exec_args", conf.ExecArgs},
+			{"profile_dir", conf.ProfileDir},
+			{"profile_dir_exists", profileDirExist},
+			{"broken_desktop_entries", brokenEntries},
+		})
+	}
 	return nil
 }
+
+// findBrokenDesktopEntries returns paths of desktop and autostart entries with missing TryExec executables
+func findBrokenDesktopEntries() ([]string, error) {
+	dir := getDesktopEntriesDir()
+	names, err := desktop.List(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	var paths []string
+	for _, name := range names {
+		paths = append(paths, desktop.Path(dir, name))
+	}
+	autostartDir := getAutostartDir()
+	autostartNames, err := desktop.ListAutostart(autostartDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, name := range autostartNames {
+		paths = append(paths, desktop.AutostartPath(autostartDir, name))
+	}
+	for _, entryPath := range []string{desktop.CombinedPath(dir), desktop.HandlerPath(dir)} {
+		exist, err := util.Exist(entryPath)
+		if err != nil {
+			return nil, err
+		}
+		if exist {
+			paths = append(paths, entryPath)
+		}
+	}
+	var broken []string
+	for _, entryPath := range paths {
+		tryExec, err := desktop.ReadTryExec(entryPath)
+		if err != nil {
+			return nil, err
+		}
+		if tryExec != "" && !isExecutableExist(tryExec) {
+			broken = append(broken, entryPath)
+		}
+	}
+	return broken, nil
+}
+
+func isExecutableExist(name string) bool {
+	if filepath.IsAbs(name) {
+		_, err := os.Stat(name)
+		return err == nil
+	}
+	_, err := exec.LookPath(name)
+	return err == nil
+}
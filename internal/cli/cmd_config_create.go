@@ -72,6 +72,14 @@ func (c *configCreateCmd) Execute(args []string) error {
 	profileDir := getDefaultProfileDir(dataDir)
 	printMessage("Profile directory: %s", profileDir)
 	conf.ProfileDir = profileDir
+	if exist {
+		operations := []string{"rewrite config " + configPath}
+		if ok, err := confirmAction("Rewrite the existing config?", operations); !ok {
+			return err
+		}
+	} else if dryRun("write config " + configPath) {
+		return nil
+	}
 	if err = conf.Write(); err != nil {
 		return newError("Failed to write config.", err)
 	}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/un-def/manygram/internal/config"
+	"github.com/un-def/manygram/internal/desktop"
+	"github.com/un-def/manygram/internal/profile"
+)
+
+func init() {
+	parser.AddCommand("copy", "Copy the profile", `
+		Copy the profile into a new one.
+		Use --no-session to make the copy start logged out
+		and --no-cache to skip media caches and logs.
+		The display name, the window class and the desktop entry
+		and autostart flags are not copied.
+	`, new(copyCmd))
+}
+
+type copyCmd struct {
+	Args struct {
+		Source      string `description:"Source profile name" positional-arg-name:"SRC"`
+		Destination string `description:"New profile name" positional-arg-name:"DST"`
+	} `positional-args:"true" required:"true"`
+	NoSession bool `short:"s" long:"no-session" description:"Do not copy session and key data"`
+	NoCache   bool `short:"c" long:"no-cache" description:"Do not copy media caches and logs"`
+	Desktop   bool `short:"d" long:"desktop" description:"Also create a desktop entry"`
+}
+
+func (c *copyCmd) Execute(args []string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	srcName, dstName := c.Args.Source, c.Args.Destination
+	if options.DryRun {
+		return c.dryRun(conf.ProfileDir)
+	}
+	opts := &profile.CopyOptions{SkipSession: c.NoSession, SkipCache: c.NoCache}
+	dst, err := profile.Copy(conf.ProfileDir, srcName, dstName, opts)
+	if err != nil {
+		return newCopyError(srcName, dstName, err)
+	}
+	printMessage("Profile '%s' has been copied to '%s'.", srcName, dstName)
+	if err := resetCopiedProfileConfig(dst); err != nil {
+		return err
+	}
+	if c.Desktop {
+		if err := createDesktopEntry(conf, dstName); err != nil {
+			return err
+		}
+		printMessage("Desktop entry for profile has been created.")
+	}
+	return updateCombinedDesktopEntry(conf)
+}
+
+// dryRun checks the profiles and prints the operations of the copy
+func (c *copyCmd) dryRun(dir string) error {
+	srcName, dstName := c.Args.Source, c.Args.Destination
+	src, err := profile.Read(dir, srcName)
+	if err == nil {
+		err = profile.CheckNew(dir, dstName)
+	}
+	if err != nil {
+		return newCopyError(srcName, dstName, err)
+	}
+	if err := checkNotRunning(src); err != nil {
+		return err
+	}
+	operations := []string{fmt.Sprintf("copy profile directory %s to %s", src.Path, profile.Path(dir, dstName))}
+	if c.Desktop {
+		operations = append(operations, "create desktop entry "+desktop.Path(getDesktopEntriesDir(), dstName))
+	}
+	dryRun(withCombinedUpdate(operations)...)
+	return nil
+}
+
+// resetCopiedProfileConfig clears the parameters of the copied profile config that must not be shared
+// with the source profile, the desktop entry flag is set again if the entry is created
+func resetCopiedProfileConfig(prof *profile.Profile) error {
+	profConf, err := readProfileConfig(prof)
+	if err != nil {
+		return err
+	}
+	if !profConf.DesktopEntry && !profConf.Autostart && profConf.WMClass == "" && profConf.DisplayName == "" {
+		return nil
+	}
+	profConf.DesktopEntry = false
+	profConf.Autostart = false
+	profConf.WMClass = ""
+	profConf.DisplayName = ""
+	if err := profConf.Write(); err != nil {
+		return newError("Failed to write profile config %s", config.ProfileConfigPath(prof.Path), err)
+	}
+	return nil
+}
+
+func newCopyError(srcName string, dstName string, err error) error {
+	if errors.Is(err, profile.ErrInvalidName) {
+		if profile.IsValidName(srcName) {
+			return profileNameError(dstName)
+		}
+		return profileNameError(srcName)
+	}
+	if errors.Is(err, profile.ErrNotExist) {
+		return newError("Profile '%s' does not exist.", srcName)
+	}
+	if errors.Is(err, profile.ErrAlreadyExists) {
+		return newError("Profile '%s' already exists.", dstName)
+	}
+	if errors.Is(err, profile.ErrRunning) {
+		return newError("Profile '%s' is running. Close Telegram Desktop first.", srcName)
+	}
+	return newError("Failed to copy profile '%s'.", srcName, err)
+}
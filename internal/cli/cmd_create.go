@@ -3,6 +3,7 @@ package cli
 import (
 	"errors"
 
+	"github.com/un-def/manygram/internal/desktop"
 	"github.com/un-def/manygram/internal/profile"
 )
 
@@ -21,19 +22,19 @@ func (c *createCmd) Execute(args []string) error {
 		return err
 	}
 	profileName := c.Profile.Name
-	_, err = profile.Create(conf.ProfileDir, profileName)
-	if err != nil {
-		if errors.Is(err, profile.ErrInvalidName) {
-			return profileNameError(profileName)
-		}
-		if errors.Is(err, profile.ErrAlreadyExists) {
-			return newError(
-				"Profile '%s' already exists. Use `manygram remove %[1]s` first if you want to recreate the profile.",
-				profileName,
-			)
-		}
-		return newError("Failed to create profile '%s'.", profileName, err)
-
+	dir := conf.ProfileDir
+	if err := profile.CheckNew(dir, profileName); err != nil {
+		return newCreateError(profileName, err)
+	}
+	operations := []string{"create profile directory " + profile.Path(dir, profileName)}
+	if c.Desktop {
+		operations = append(operations, "create desktop entry "+desktop.Path(getDesktopEntriesDir(), profileName))
+	}
+	if dryRun(withCombinedUpdate(operations)...) {
+		return nil
+	}
+	if _, err = profile.Create(dir, profileName); err != nil {
+		return newCreateError(profileName, err)
 	}
 	printMessage("Profile '%s' has been created.", profileName)
 	if c.Desktop {
@@ -42,5 +43,18 @@ func (c *createCmd) Execute(args []string) error {
 		}
 		printMessage("Desktop entry for profile has been created.")
 	}
-	return nil
+	return updateCombinedDesktopEntry(conf)
+}
+
+func newCreateError(profileName string, err error) error {
+	if errors.Is(err, profile.ErrInvalidName) {
+		return profileNameError(profileName)
+	}
+	if errors.Is(err, profile.ErrAlreadyExists) {
+		return newError(
+			"Profile '%s' already exists. Use `manygram remove %[1]s` first if you want to recreate the profile.",
+			profileName,
+		)
+	}
+	return newError("Failed to create profile '%s'.", profileName, err)
 }
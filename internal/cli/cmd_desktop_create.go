@@ -1,5 +1,14 @@
 package cli
 
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/un-def/manygram/internal/config"
+	"github.com/un-def/manygram/internal/desktop"
+)
+
 func init() {
 	desktopCommand.AddCommand(
 		"create", "Create a new desktop entry", "Create a new desktop entry.",
@@ -8,14 +17,134 @@ func init() {
 }
 
 type desktopCreateCmd struct {
-	profileOption
+	optionalProfileOption
+	WMClass  string `short:"w" long:"wm-class" description:"Window class of the profile (default: manygram-PROFILE)" value-name:"CLASS"`
+	Combined bool   `short:"c" long:"combined" description:"Create a single entry with an action for every profile"`
+	Handler  bool   `short:"u" long:"handler" description:"Create an entry handling tg:// links and make it the default handler"`
 }
 
 func (c *desktopCreateCmd) Execute(args []string) error {
 	profileName := c.Profile.Name
+	if countTrue(profileName != "", c.Combined, c.Handler) != 1 {
+		return newError("Specify either a profile name, --combined or --handler.")
+	}
+	if c.WMClass != "" && profileName == "" {
+		return newError("--wm-class can only be used with a profile name.")
+	}
+	if c.WMClass != "" && !config.IsValidWMClass(c.WMClass) {
+		return newError("Invalid window class '%s'. Use letters, digits, dots, underscores and hyphens.", c.WMClass)
+	}
+	if c.Combined {
+		return c.createCombined()
+	}
+	if c.Handler {
+		return c.createHandler()
+	}
+	exist, err := desktop.Exist(getDesktopEntriesDir(), profileName)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return newError("Desktop entry for profile '%s' already exists.", profileName)
+	}
+	if options.DryRun {
+		return c.dryRun(profileName)
+	}
+	if c.WMClass != "" {
+		if err := setWMClass(profileName, c.WMClass); err != nil {
+			return err
+		}
+	}
 	if err := createDesktopEntry(nil, profileName); err != nil {
 		return err
 	}
 	printMessage("Desktop entry for profile '%s' has been created.", profileName)
 	return nil
 }
+
+// dryRun checks the profile and prints the operations of the desktop entry creation
+func (c *desktopCreateCmd) dryRun(profileName string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	prof, err := readProfile(conf.ProfileDir, profileName)
+	if err != nil {
+		return err
+	}
+	operations := []string{
+		"write profile config " + config.ProfileConfigPath(prof.Path),
+		"write desktop entry " + desktop.Path(getDesktopEntriesDir(), profileName),
+	}
+	dryRun(operations...)
+	return nil
+}
+
+func (c *desktopCreateCmd) createCombined() error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	if dryRun("write combined desktop entry " + desktop.CombinedPath(getDesktopEntriesDir())) {
+		return nil
+	}
+	if err := writeCombinedDesktopEntry(conf); err != nil {
+		return err
+	}
+	printMessage("Combined desktop entry has been created.")
+	return nil
+}
+
+func (c *desktopCreateCmd) createHandler() error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	operations := []string{
+		"write URL handler desktop entry " + desktop.HandlerPath(getDesktopEntriesDir()),
+		fmt.Sprintf("run `xdg-mime default %s %s`", desktop.HandlerName, desktop.HandlerMimeType),
+	}
+	if dryRun(operations...) {
+		return nil
+	}
+	if err := writeHandlerDesktopEntry(conf); err != nil {
+		return err
+	}
+	printMessage("URL handler desktop entry has been created.")
+	xdgMime, err := exec.LookPath("xdg-mime")
+	if err != nil {
+		printMessage(
+			"xdg-mime is not found. Make %s the default handler of %s manually.",
+			desktop.HandlerName, desktop.HandlerMimeType,
+		)
+		return nil
+	}
+	cmd := exec.Command(xdgMime, "default", desktop.HandlerName, desktop.HandlerMimeType)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return newError("Failed to make manygram the default handler of tg:// links.", err)
+	}
+	printMessage("manygram is now the default handler of tg:// links.")
+	return nil
+}
+
+// setWMClass stores the window class in the per-profile config to make `run` use it as well
+func setWMClass(profileName string, wmClass string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	prof, err := readProfile(conf.ProfileDir, profileName)
+	if err != nil {
+		return err
+	}
+	profConf, err := readProfileConfig(prof)
+	if err != nil {
+		return err
+	}
+	profConf.WMClass = wmClass
+	if err := profConf.Write(); err != nil {
+		return newError("Failed to write profile config %s", config.ProfileConfigPath(prof.Path), err)
+	}
+	return nil
+}
@@ -1,5 +1,13 @@
 package cli
 
+import (
+	"errors"
+	"os"
+
+	"github.com/un-def/manygram/internal/desktop"
+	"github.com/un-def/manygram/internal/profile"
+)
+
 func init() {
 	desktopCommand.AddCommand(
 		"remove", "Remove the desktop entry", "Remove the desktop entry.",
@@ -8,15 +16,83 @@ func init() {
 }
 
 type desktopRemoveCmd struct {
-	profileOption
+	optionalProfileOption
+	Force    bool `short:"f" long:"force" description:"Remove the desktop entry even if the profile is running"`
+	Combined bool `short:"c" long:"combined" description:"Remove the combined entry"`
+	Handler  bool `short:"u" long:"handler" description:"Remove the entry handling tg:// links"`
 }
 
 func (c *desktopRemoveCmd) Execute(args []string) error {
 	profileName := c.Profile.Name
-	err := removeDesktopEntry(profileName)
+	if countTrue(profileName != "", c.Combined, c.Handler) != 1 {
+		return newError("Specify either a profile name, --combined or --handler.")
+	}
+	if c.Combined {
+		return removeCombinedDesktopEntry()
+	}
+	if c.Handler {
+		return removeHandlerDesktopEntry()
+	}
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
+	operations := []string{"remove desktop entry " + desktop.Path(getDesktopEntriesDir(), profileName)}
+	prof, err := profile.Read(conf.ProfileDir, profileName)
+	// the desktop entry of the already removed profile can be removed
+	if err == nil {
+		if !c.Force {
+			running, err := prof.IsRunning()
+			if err != nil {
+				return newError("Failed to check whether profile '%s' is running.", profileName, err)
+			}
+			if running {
+				return newError(
+					"Profile '%s' is running. Close Telegram Desktop first or use --force to remove the desktop entry anyway.",
+					profileName,
+				)
+			}
+		}
+		if dryRun(operations...) {
+			return nil
+		}
+		if err := setDesktopEntryFlag(prof, false); err != nil {
+			return err
+		}
+	} else if dryRun(operations...) {
+		return nil
+	}
+	if err := removeDesktopEntry(profileName); err != nil {
+		return err
+	}
 	printMessage("Desktop entry for profile '%s' has been removed.", profileName)
 	return nil
 }
+
+func removeCombinedDesktopEntry() error {
+	if dryRun("remove combined desktop entry " + desktop.CombinedPath(getDesktopEntriesDir())) {
+		return nil
+	}
+	err := desktop.RemoveCombined(getDesktopEntriesDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return newError("Combined desktop entry does not exist.")
+	} else if err != nil {
+		return newError("Failed to remove combined desktop entry.", err)
+	}
+	printMessage("Combined desktop entry has been removed.")
+	return nil
+}
+
+func removeHandlerDesktopEntry() error {
+	if dryRun("remove URL handler desktop entry " + desktop.HandlerPath(getDesktopEntriesDir())) {
+		return nil
+	}
+	err := desktop.RemoveHandler(getDesktopEntriesDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return newError("URL handler desktop entry does not exist.")
+	} else if err != nil {
+		return newError("Failed to remove URL handler desktop entry.", err)
+	}
+	printMessage("URL handler desktop entry has been removed.")
+	return nil
+}
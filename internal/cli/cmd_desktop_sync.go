@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"errors"
+	"os"
+
+	"github.com/un-def/manygram/internal/config"
+	"github.com/un-def/manygram/internal/desktop"
+	"github.com/un-def/manygram/internal/profile"
+)
+
+func init() {
+	desktopCommand.AddCommand(
+		"sync", "Synchronize desktop entries with profiles", `
+		Synchronize desktop entries with profiles.
+		Entries of removed profiles are removed, missing entries of profiles
+		created with a desktop entry are created, outdated entries are rewritten.
+	`, new(desktopSyncCmd))
+}
+
+type desktopSyncCmd struct {
+	DryRun bool `short:"n" description:"Only print planned changes, same as global --dry-run"`
+}
+
+func (c *desktopSyncCmd) Execute(args []string) error {
+	c.DryRun = c.DryRun || options.DryRun
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	dir := getDesktopEntriesDir()
+	execPath, err := getDesktopExecPath(conf)
+	if err != nil {
+		return err
+	}
+	profiles, err := listProfiles(conf.ProfileDir)
+	if err != nil {
+		return err
+	}
+	names, err := desktop.List(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return newError("Failed to list desktop entries in %s.", dir, err)
+	}
+	existing := make(map[string]bool, len(profiles))
+	for _, prof := range profiles {
+		existing[prof.Name] = true
+	}
+	hasEntry := make(map[string]bool, len(names))
+	changed := false
+	for _, name := range names {
+		hasEntry[name] = true
+		if existing[name] {
+			continue
+		}
+		changed = true
+		if c.DryRun {
+			printMessage("Would remove desktop entry for removed profile '%s'.", name)
+			continue
+		}
+		if err := removeDesktopEntry(name); err != nil {
+			return err
+		}
+		printMessage("Desktop entry for removed profile '%s' has been removed.", name)
+	}
+	for _, prof := range profiles {
+		profConf, err := readProfileConfig(prof)
+		if err != nil {
+			return err
+		}
+		entry := newDesktopEntry(execPath, prof, profConf)
+		if !hasEntry[prof.Name] {
+			if !profConf.DesktopEntry {
+				continue
+			}
+			changed = true
+			if c.DryRun {
+				printMessage("Would create desktop entry for profile '%s'.", prof.Name)
+				continue
+			}
+			if err := writeDesktopEntry(conf, prof); err != nil {
+				return err
+			}
+			printMessage("Desktop entry for profile '%s' has been created.", prof.Name)
+			continue
+		}
+		stale, err := desktop.IsStale(dir, entry)
+		if err != nil {
+			return newError("Failed to read desktop entry for profile '%s'.", prof.Name, err)
+		}
+		if !stale {
+			continue
+		}
+		changed = true
+		if c.DryRun {
+			printMessage("Would update desktop entry for profile '%s'.", prof.Name)
+			continue
+		}
+		if err := writeDesktopEntry(conf, prof); err != nil {
+			return err
+		}
+		printMessage("Desktop entry for profile '%s' has been updated.", prof.Name)
+	}
+	combinedChanged, err := c.syncCombined(conf)
+	if err != nil {
+		return err
+	}
+	handlerChanged, err := c.syncHandler(conf, execPath)
+	if err != nil {
+		return err
+	}
+	autostartChanged, err := c.syncAutostart(conf, execPath, profiles)
+	if err != nil {
+		return err
+	}
+	if !changed && !combinedChanged && !handlerChanged && !autostartChanged {
+		printMessage("Desktop entries are up to date.")
+	}
+	return nil
+}
+
+func (c *desktopSyncCmd) syncCombined(conf *config.Config) (bool, error) {
+	dir := getDesktopEntriesDir()
+	exist, err := desktop.CombinedExist(dir)
+	if err != nil || !exist {
+		return false, err
+	}
+	entries, err := newCombinedDesktopEntries(conf)
+	if err != nil {
+		return false, err
+	}
+	if len(entries) == 0 {
+		if c.DryRun {
+			printMessage("Would remove combined desktop entry.")
+			return true, nil
+		}
+		return true, updateCombinedDesktopEntry(conf)
+	}
+	stale, err := desktop.IsCombinedStale(dir, entries)
+	if err != nil {
+		return false, newError("Failed to read combined desktop entry.", err)
+	}
+	if !stale {
+		return false, nil
+	}
+	if c.DryRun {
+		printMessage("Would update combined desktop entry.")
+		return true, nil
+	}
+	if err := writeCombinedDesktopEntry(conf); err != nil {
+		return false, err
+	}
+	printMessage("Combined desktop entry has been updated.")
+	return true, nil
+}
+
+func (c *desktopSyncCmd) syncHandler(conf *config.Config, execPath string) (bool, error) {
+	dir := getDesktopEntriesDir()
+	exist, err := desktop.HandlerExist(dir)
+	if err != nil || !exist {
+		return false, err
+	}
+	stale, err := desktop.IsHandlerStale(dir, execPath, newHandlerExec(execPath))
+	if err != nil {
+		return false, newError("Failed to read URL handler desktop entry.", err)
+	}
+	if !stale {
+		return false, nil
+	}
+	if c.DryRun {
+		printMessage("Would update URL handler desktop entry.")
+		return true, nil
+	}
+	if err := writeHandlerDesktopEntry(conf); err != nil {
+		return false, err
+	}
+	printMessage("URL handler desktop entry has been updated.")
+	return true, nil
+}
+
+func (c *desktopSyncCmd) syncAutostart(conf *config.Config, execPath string, profiles []*profile.Profile) (bool, error) {
+	dir := getAutostartDir()
+	names, err := desktop.ListAutostart(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, newError("Failed to list autostart entries in %s.", dir, err)
+	}
+	profilesByName := make(map[string]*profile.Profile, len(profiles))
+	for _, prof := range profiles {
+		profilesByName[prof.Name] = prof
+	}
+	changed := false
+	for _, name := range names {
+		prof, ok := profilesByName[name]
+		if !ok {
+			changed = true
+			if c.DryRun {
+				printMessage("Would remove autostart entry for removed profile '%s'.", name)
+				continue
+			}
+			if _, err := removeAutostartEntry(name); err != nil {
+				return false, err
+			}
+			if err := removeProfileIcon(name); err != nil {
+				return false, err
+			}
+			printMessage("Autostart entry for removed profile '%s' has been removed.", name)
+			continue
+		}
+		profConf, err := readProfileConfig(prof)
+		if err != nil {
+			return false, err
+		}
+		stale, err := desktop.IsAutostartStale(dir, newAutostartEntry(execPath, prof, profConf))
+		if err != nil {
+			return false, newError("Failed to read autostart entry for profile '%s'.", name, err)
+		}
+		if !stale {
+			continue
+		}
+		changed = true
+		if c.DryRun {
+			printMessage("Would update autostart entry for profile '%s'.", name)
+			continue
+		}
+		if err := writeAutostartEntry(conf, prof); err != nil {
+			return false, err
+		}
+		printMessage("Autostart entry for profile '%s' has been updated.", name)
+	}
+	return changed, nil
+}
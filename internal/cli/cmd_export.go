@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/un-def/manygram/internal/archive"
+	"github.com/un-def/manygram/internal/crypt"
+	"github.com/un-def/manygram/internal/profile"
+	"github.com/un-def/manygram/internal/util"
+)
+
+func init() {
+	parser.AddCommand("export", "Export the profile", `
+		Export the profile as a portable archive.
+		The compression type is determined by the file extension,
+		.tar.gz (.tgz) and .tar.zst are supported.
+		The archive is encrypted with a passphrase if --encrypt is specified
+		or the file name ends with .enc extension, the passphrase is read from
+		--passphrase-file, $MANYGRAM_PASSPHRASE, 'passphrase-file' config parameter
+		or asked interactively.
+	`, new(exportCmd))
+}
+
+type exportCmd struct {
+	profileOption
+	passphraseOption
+	OutputFile string `short:"o" long:"output-file" description:"Archive path (default: PROFILE.tar.gz or PROFILE.tar.gz.enc)" value-name:"FILE"`
+	NoSession  bool   `short:"s" long:"no-session" description:"Do not export session and key data"`
+	NoCache    bool   `short:"c" long:"no-cache" description:"Do not export media caches and logs"`
+	Force      bool   `short:"f" long:"force" description:"Overwrite the existing archive"`
+	Encrypt    bool   `short:"e" long:"encrypt" description:"Encrypt the archive with a passphrase"`
+}
+
+func (c *exportCmd) Execute(args []string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	profileName := c.Profile.Name
+	prof, err := readProfile(conf.ProfileDir, profileName)
+	if err != nil {
+		return err
+	}
+	if err := checkNotRunning(prof); err != nil {
+		return err
+	}
+	archivePath := c.OutputFile
+	if archivePath == "" {
+		archivePath = profileName + ".tar.gz"
+		if c.Encrypt {
+			archivePath += crypt.Extension
+		}
+	}
+	encrypt := c.Encrypt || strings.HasSuffix(archivePath, crypt.Extension)
+	compression, err := archive.CompressionFromPath(strings.TrimSuffix(archivePath, crypt.Extension))
+	if err != nil {
+		return newError("Unknown archive type. Use .tar.gz or .tar.zst extension optionally followed by .enc.", err)
+	}
+	exist, err := util.Exist(archivePath)
+	if err != nil {
+		return err
+	}
+	if exist && !c.Force {
+		return newError("File %s already exists. Use --force to overwrite it.", archivePath)
+	}
+	if exist {
+		operations := []string{"overwrite archive " + archivePath}
+		if ok, err := confirmAction("Overwrite the existing archive?", operations); !ok {
+			return err
+		}
+	} else if dryRun("write archive " + archivePath) {
+		return nil
+	}
+	var passphrase []byte
+	if encrypt {
+		if passphrase, err = c.readPassphrase(conf, true); err != nil {
+			return err
+		}
+	}
+	manifest := &archive.Manifest{
+		Version:   manygramVersion,
+		Profile:   profileName,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Flavor:    detectFlavor(conf),
+	}
+	opts := &profile.CopyOptions{SkipSession: c.NoSession, SkipCache: c.NoCache}
+	if err := writeArchive(archivePath, compression, prof.Path, manifest, opts.Skip, passphrase); err != nil {
+		return newError("Failed to export profile '%s'.", profileName, err)
+	}
+	printMessage("Profile '%s' has been exported to %s.", profileName, archivePath)
+	return nil
+}
+
+// writeArchive writes the archive into a temporary file and moves it
+// into place on success to never leave a truncated archive behind,
+// the archive is encrypted if the passphrase is not nil
+func writeArchive(
+	archivePath string, compression archive.Compression, dir string,
+	manifest *archive.Manifest, skip func(string) bool, passphrase []byte,
+) error {
+	tmpPath := archivePath + ".part"
+	file, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if passphrase == nil {
+		err = archive.Write(file, compression, dir, manifest, skip)
+	} else {
+		err = writeEncryptedArchive(file, compression, dir, manifest, skip, passphrase)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, archivePath)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+func writeEncryptedArchive(
+	file *os.File, compression archive.Compression, dir string,
+	manifest *archive.Manifest, skip func(string) bool, passphrase []byte,
+) error {
+	w, err := crypt.NewWriter(file, passphrase, crypt.DefaultParams)
+	if err != nil {
+		return err
+	}
+	if err := archive.Write(w, compression, dir, manifest, skip); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func checkNotRunning(prof *profile.Profile) error {
+	running, err := prof.IsRunning()
+	if err != nil {
+		return newError("Failed to check whether profile '%s' is running.", prof.Name, err)
+	}
+	if running {
+		return newError("Profile '%s' is running. Close Telegram Desktop first.", prof.Name)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/un-def/manygram/internal/archive"
+	"github.com/un-def/manygram/internal/desktop"
+	"github.com/un-def/manygram/internal/profile"
+	"github.com/un-def/manygram/internal/util"
+)
+
+func init() {
+	parser.AddCommand("import", "Import the profile", `
+		Import the profile from an archive created by 'manygram export'.
+		The profile name is taken from the archive unless --name is specified.
+		The passphrase of the encrypted archive is read from --passphrase-file,
+		$MANYGRAM_PASSPHRASE, 'passphrase-file' config parameter or asked interactively.
+	`, new(importCmd))
+}
+
+type importCmd struct {
+	passphraseOption
+	Args struct {
+		Archive string `description:"Archive path" positional-arg-name:"ARCHIVE"`
+	} `positional-args:"true" required:"true"`
+	Name    string `short:"n" long:"name" description:"New profile name" value-name:"NAME"`
+	Desktop bool   `short:"d" long:"desktop" description:"Also create a desktop entry"`
+}
+
+func (c *importCmd) Execute(args []string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	archivePath := c.Args.Archive
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return newError("Failed to open archive %s.", archivePath, err)
+	}
+	defer file.Close()
+	ar, err := c.newArchiveReader(conf, file)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+	manifest := ar.Manifest
+	printMessage(
+		"Archive of profile '%s' created at %s by manygram %s (%s Telegram Desktop).",
+		manifest.Profile, manifest.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+		manifest.Version, manifest.Flavor,
+	)
+	if flavor := detectFlavor(conf); flavor != manifest.Flavor {
+		printMessage("Warning: current Telegram Desktop installation type is %s.", flavor)
+	}
+	profileName := c.Name
+	if profileName == "" {
+		profileName = manifest.Profile
+	}
+	if err := profile.CheckNew(conf.ProfileDir, profileName); err != nil {
+		return newImportError(profileName, err)
+	}
+	operations := []string{fmt.Sprintf(
+		"extract archive %s to profile directory %s", archivePath, profile.Path(conf.ProfileDir, profileName),
+	)}
+	if c.Desktop {
+		operations = append(operations, "create desktop entry "+desktop.Path(getDesktopEntriesDir(), profileName))
+	}
+	if dryRun(withCombinedUpdate(operations)...) {
+		return nil
+	}
+	if err := importProfile(ar, conf.ProfileDir, profileName); err != nil {
+		return newImportError(profileName, err)
+	}
+	printMessage("Profile '%s' has been imported.", profileName)
+	if c.Desktop {
+		if err := createDesktopEntry(conf, profileName); err != nil {
+			return err
+		}
+		printMessage("Desktop entry for profile has been created.")
+	}
+	return updateCombinedDesktopEntry(conf)
+}
+
+func newImportError(profileName string, err error) error {
+	if errors.Is(err, profile.ErrInvalidName) {
+		return profileNameError(profileName)
+	}
+	if errors.Is(err, profile.ErrAlreadyExists) {
+		return newError(
+			"Profile '%s' already exists. Use --name to import the profile under another name.",
+			profileName,
+		)
+	}
+	return newError("Failed to import profile '%s'.", profileName, err)
+}
+
+// importProfile extracts the archive into a temporary directory next to
+// the profile and then renames it to make the profile appear atomically
+func importProfile(ar *archive.Reader, profileDir string, profileName string) error {
+	profilePath := profile.Path(profileDir, profileName)
+	exist, err := util.Exist(profilePath)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return profile.ErrAlreadyExists
+	}
+	tmpDir, err := extractProfile(ar, profileDir)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpDir, profilePath); err != nil {
+		os.RemoveAll(tmpDir)
+		return err
+	}
+	return nil
+}
+
+// extractProfile extracts the archive into a temporary directory in the profile directory,
+// the caller must rename the returned directory to the profile path or remove it
+func extractProfile(ar *archive.Reader, profileDir string) (string, error) {
+	if err := os.MkdirAll(profileDir, 0755); err != nil {
+		return "", err
+	}
+	tmpDir, err := ioutil.TempDir(profileDir, ".import-")
+	if err != nil {
+		return "", err
+	}
+	err = ar.Extract(tmpDir)
+	if err == nil {
+		err = os.Chmod(tmpDir, 0755)
+	}
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return "", err
+	}
+	return tmpDir, nil
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/un-def/manygram/internal/desktop"
+	"github.com/un-def/manygram/internal/profile"
+)
+
+func init() {
+	parser.AddCommand("list", "List profiles", "List profiles and their status.", new(listCmd))
+}
+
+type listCmd struct{}
+
+type profileStatus struct {
+	Name    string
+	Path    string
+	Size    int64
+	Desktop bool
+	Running bool
+}
+
+var profileStatusKeys = []string{"name", "path", "size", "desktop", "running"}
+
+func (s *profileStatus) record() record {
+	return record{
+		{"name", s.Name},
+		{"path", s.Path},
+		{"size", s.Size},
+		{"desktop", s.Desktop},
+		{"running", s.Running},
+	}
+}
+
+func (c *listCmd) Execute(args []string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	profiles, err := profile.List(conf.ProfileDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return newError("Failed to list profiles in %s.", conf.ProfileDir, err)
+	}
+	desktopEntriesDir := getDesktopEntriesDir()
+	statuses := make([]*profileStatus, len(profiles))
+	for idx, prof := range profiles {
+		size, err := prof.Size()
+		if err != nil {
+			return newError("Failed to calculate size of profile '%s'.", prof.Name, err)
+		}
+		hasDesktop, err := desktop.Exist(desktopEntriesDir, prof.Name)
+		if err != nil {
+			return newError("Failed to check desktop entry for profile '%s'.", prof.Name, err)
+		}
+		running, err := prof.IsRunning()
+		if err != nil {
+			return newError("Failed to check whether profile '%s' is running.", prof.Name, err)
+		}
+		statuses[idx] = &profileStatus{prof.Name, prof.Path, size, hasDesktop, running}
+	}
+	if !isTextOutput() {
+		records := make([]record, len(statuses))
+		for idx, status := range statuses {
+			records[idx] = status.record()
+		}
+		return printRecords(profileStatusKeys, records)
+	}
+	if len(statuses) == 0 {
+		printMessage("No profiles found in %s.", conf.ProfileDir)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSIZE\tDESKTOP\tRUNNING\tPATH")
+	for _, status := range statuses {
+		fmt.Fprintf(
+			w, "%s\t%s\t%s\t%s\t%s\n", status.Name, formatSize(status.Size),
+			formatBool(status.Desktop), formatBool(status.Running), status.Path,
+		)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"github.com/un-def/manygram/internal/config"
+)
+
+const logWriterCommand = "log-writer"
+
+func init() {
+	cmd, _ := parser.AddCommand(logWriterCommand, "Write stdin to the profile log", `
+		Copy stdin to the log file of the profile rotating it.
+		The command is started by 'manygram run' to capture the output of detached Telegram Desktop
+		and exits when all processes writing to the pipe are closed.
+	`, new(logWriterCmd))
+	cmd.Hidden = true
+}
+
+type logWriterCmd struct {
+	profileOption
+	MaxSize  int64 `long:"max-size" required:"true" description:"Maximum size of the log file in bytes" value-name:"BYTES"`
+	MaxFiles int   `long:"max-files" required:"true" description:"Number of rotated log files to keep" value-name:"N"`
+}
+
+func (c *logWriterCmd) Execute(args []string) error {
+	conf := &config.Config{LogMaxSize: c.MaxSize, LogMaxFiles: c.MaxFiles}
+	logWriter, err := openProfileLog(conf, c.Profile.Name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(logWriter, os.Stdin)
+	if closeErr := logWriter.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return newError("Failed to write log file %s.", getLogPath(c.Profile.Name), err)
+	}
+	return nil
+}
+
+// startLogWriter starts `manygram log-writer` in a new session to let it outlive the current process,
+// the returned pipe must be passed to the detached process and closed by the caller
+func startLogWriter(conf *config.Config, profileName string) (*os.File, error) {
+	execPath, err := os.Executable()
+	if err != nil {
+		return nil, newError("Failed to determine manygram executable path.", err)
+	}
+	maxSize, maxFiles := getLogLimits(conf)
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, newError("Failed to create log pipe.", err)
+	}
+	defer r.Close()
+	cmd := exec.Command(
+		execPath, logWriterCommand,
+		"--max-size", strconv.FormatInt(maxSize, 10), "--max-files", strconv.Itoa(maxFiles),
+		profileName,
+	)
+	cmd.Stdin = r
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		w.Close()
+		return nil, newError("Failed to start log writer.", err)
+	}
+	cmd.Process.Release()
+	return w, nil
+}
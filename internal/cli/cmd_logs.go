@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/un-def/manygram/internal/logfile"
+	"github.com/un-def/manygram/internal/profile"
+)
+
+func init() {
+	parser.AddCommand("logs", "Show Telegram Desktop log", `
+		Show the output of Telegram Desktop captured to the log file
+		of the profile. Enable logging with the 'log' config parameter.
+	`, new(logsCmd))
+}
+
+type logsCmd struct {
+	profileOption
+	Follow bool `short:"f" long:"follow" description:"Output appended data as the log grows"`
+	Lines  int  `short:"n" long:"lines" description:"Output the last N lines (default: all)" value-name:"N"`
+}
+
+func (c *logsCmd) Execute(args []string) error {
+	profileName := c.Profile.Name
+	if !profile.IsValidName(profileName) {
+		return profileNameError(profileName)
+	}
+	logPath := getLogPath(profileName)
+	content, err := logfile.Tail(logPath, c.Lines)
+	if os.IsNotExist(err) {
+		if !c.Follow {
+			return newError(
+				"Log of profile '%s' does not exist. Set `log = true` in the config to enable logging.",
+				profileName,
+			)
+		}
+	} else if err != nil {
+		return newError("Failed to read log file %s.", logPath, err)
+	}
+	if _, err := os.Stdout.Write(content); err != nil {
+		return err
+	}
+	if !c.Follow {
+		return nil
+	}
+	var offset int64
+	if info, err := os.Stat(logPath); err == nil {
+		offset = info.Size()
+	}
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		if _, ok := <-signals; ok {
+			close(stop)
+		}
+	}()
+	if err := logfile.Follow(logPath, offset, os.Stdout, 500*time.Millisecond, stop); err != nil {
+		return newError("Failed to follow log file %s.", logPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"github.com/un-def/manygram/internal/config"
+	"github.com/un-def/manygram/internal/profile"
+	"github.com/un-def/manygram/internal/tg"
+)
+
+func init() {
+	parser.AddCommand("open", "Open tg:// link", `
+		Open tg:// or https://t.me/ link with Telegram Desktop.
+		The profile is selected according to the 'open-mode' config parameter
+		unless --profile is specified.
+	`, new(openCmd))
+}
+
+type openCmd struct {
+	Args struct {
+		URL string `description:"Link to open" positional-arg-name:"URL"`
+	} `positional-args:"true" required:"true"`
+	Profile string `short:"p" long:"profile" description:"Profile opening the link" value-name:"PROFILE"`
+}
+
+func (c *openCmd) Execute(args []string) error {
+	url, err := tg.NormalizeURL(c.Args.URL)
+	if err != nil {
+		return newError("Cannot open '%s': only tg:// and https://t.me/ links are supported.", c.Args.URL)
+	}
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	prof, err := c.selectProfile(conf)
+	if err != nil {
+		return err
+	}
+	return runProfile(conf, prof, []string{"--", url}, false)
+}
+
+// selectProfile returns the profile from --profile or selected by the open mode,
+// the only profile is used or the user is asked if the mode does not determine it
+func (c *openCmd) selectProfile(conf *config.Config) (*profile.Profile, error) {
+	if c.Profile != "" {
+		return readProfile(conf.ProfileDir, c.Profile)
+	}
+	var candidates []string
+	switch conf.OpenMode {
+	case config.OpenModeDefault:
+		if conf.DefaultProfile == "" {
+			return nil, newError("`open-mode` is '%s' but `default-profile` is not set.", config.OpenModeDefault)
+		}
+		return readProfile(conf.ProfileDir, conf.DefaultProfile)
+	case config.OpenModeLastUsed, "":
+		candidates = []string{readLastProfile(), conf.DefaultProfile}
+	}
+	profiles, err := listProfiles(conf.ProfileDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range candidates {
+		for _, prof := range profiles {
+			if prof.Name == name {
+				return prof, nil
+			}
+		}
+	}
+	if len(profiles) == 1 {
+		return profiles[0], nil
+	}
+	return pickProfile(profiles)
+}
@@ -0,0 +1,14 @@
+package cli
+
+import "github.com/jessevdk/go-flags"
+
+var profileCommand *flags.Command
+
+func init() {
+	profileCommand, _ = parser.AddCommand(
+		"profile", "Profile metadata subcommands", "Profile metadata subcommands.",
+		new(profileCmd),
+	)
+}
+
+type profileCmd struct{}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/un-def/manygram/internal/config"
+	"github.com/un-def/manygram/internal/desktop"
+	"github.com/un-def/manygram/internal/icon"
+)
+
+func init() {
+	profileCommand.AddCommand("set", "Set profile metadata", `
+		Set profile metadata stored in the per-profile config.
+		Pass an empty value to unset the field.
+		The existing desktop entry of the profile is updated.
+	`, new(profileSetCmd))
+}
+
+type profileSetCmd struct {
+	profileOption
+	DisplayName *string `short:"n" long:"display-name" description:"Name shown in menus" value-name:"NAME"`
+	Description *string `short:"D" long:"description" description:"Free-form description" value-name:"TEXT"`
+	Icon        *string `short:"i" long:"icon" description:"Icon path or icon theme name" value-name:"ICON"`
+	IconStyle   *string `short:"s" long:"icon-style" description:"Style of the generated icon: telegram or circle" value-name:"STYLE"`
+	Color       *string `short:"c" long:"color" description:"Accent color in #RRGGBB or #RGB format" value-name:"COLOR"`
+	Tags        *string `short:"t" long:"tags" description:"Comma-separated list of tags" value-name:"TAGS"`
+}
+
+func (c *profileSetCmd) Execute(args []string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	profileName := c.Profile.Name
+	prof, err := readProfile(conf.ProfileDir, profileName)
+	if err != nil {
+		return err
+	}
+	profConf, err := readProfileConfig(prof)
+	if err != nil {
+		return err
+	}
+	if c.DisplayName != nil {
+		profConf.DisplayName = strings.TrimSpace(*c.DisplayName)
+	}
+	if c.Description != nil {
+		profConf.Description = strings.TrimSpace(*c.Description)
+	}
+	if c.Icon != nil {
+		profConf.Icon = strings.TrimSpace(*c.Icon)
+	}
+	if c.IconStyle != nil {
+		style := icon.Style(strings.TrimSpace(*c.IconStyle))
+		if style != "" && style != icon.StyleTelegram && style != icon.StyleCircle {
+			return newError("Invalid icon style '%s'. Use '%s' or '%s'.", style, icon.StyleTelegram, icon.StyleCircle)
+		}
+		profConf.IconStyle = string(style)
+	}
+	if c.Color != nil {
+		color := strings.TrimSpace(*c.Color)
+		if color != "" && !config.IsValidColor(color) {
+			return newError("Invalid color '%s'. Use #RRGGBB or #RGB format.", color)
+		}
+		profConf.Color = color
+	}
+	if c.Tags != nil {
+		profConf.Tags = parseTags(*c.Tags)
+	}
+	hasDesktop, err := desktop.Exist(getDesktopEntriesDir(), profileName)
+	if err != nil {
+		return err
+	}
+	operations := []string{"write profile config " + config.ProfileConfigPath(prof.Path)}
+	if hasDesktop {
+		operations = append(operations, "update desktop entry "+desktop.Path(getDesktopEntriesDir(), profileName))
+	}
+	if dryRun(withCombinedUpdate(operations)...) {
+		return nil
+	}
+	if err := profConf.Write(); err != nil {
+		return newError("Failed to write profile config %s", config.ProfileConfigPath(prof.Path), err)
+	}
+	printMessage("Metadata of profile '%s' has been updated.", profileName)
+	if hasDesktop {
+		if err := writeDesktopEntry(conf, prof); err != nil {
+			return err
+		}
+		printMessage("Desktop entry for profile has been updated.")
+	}
+	return updateCombinedDesktopEntry(conf)
+}
+
+func parseTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
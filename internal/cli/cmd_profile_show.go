@@ -0,0 +1,50 @@
+package cli
+
+import "strings"
+
+func init() {
+	profileCommand.AddCommand(
+		"show", "Show profile metadata", "Show profile metadata.",
+		new(profileShowCmd),
+	)
+}
+
+type profileShowCmd struct {
+	profileOption
+}
+
+func (c *profileShowCmd) Execute(args []string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	prof, err := readProfile(conf.ProfileDir, c.Profile.Name)
+	if err != nil {
+		return err
+	}
+	profConf, err := readProfileConfig(prof)
+	if err != nil {
+		return err
+	}
+	if !isTextOutput() {
+		return printRecord(record{
+			{"name", prof.Name},
+			{"path", prof.Path},
+			{"display_name", profConf.DisplayName},
+			{"description", profConf.Description},
+			{"icon", profConf.Icon},
+			{"icon_style", profConf.IconStyle},
+			{"color", profConf.Color},
+			{"tags", profConf.Tags},
+		})
+	}
+	printMessage("Name: %s", prof.Name)
+	printMessage("Path: %s", prof.Path)
+	printMessage("Display name: %s", profConf.DisplayName)
+	printMessage("Description: %s", profConf.Description)
+	printMessage("Icon: %s", profConf.Icon)
+	printMessage("Icon style: %s", profConf.IconStyle)
+	printMessage("Color: %s", profConf.Color)
+	printMessage("Tags: %s", strings.Join(profConf.Tags, ", "))
+	return nil
+}
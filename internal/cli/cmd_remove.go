@@ -2,17 +2,30 @@ package cli
 
 import (
 	"errors"
+	"fmt"
 
+	"github.com/un-def/manygram/internal/desktop"
+	"github.com/un-def/manygram/internal/logfile"
 	"github.com/un-def/manygram/internal/profile"
+	"github.com/un-def/manygram/internal/systemd"
 )
 
 func init() {
-	parser.AddCommand("remove", "Remove the profile", "Remove the profile.", new(removeCmd))
+	parser.AddCommand("remove", "Remove the profile", `
+		Remove the profile. The profile directory is moved to the trash
+		($XDG_DATA_HOME/Trash or .Trash-$UID at the top of the filesystem
+		of the profile directory) and can be restored with 'manygram restore'
+		unless --permanent is specified. The autostart entry is removed
+		and recreated when the profile is restored. The manygram-PROFILE.service
+		unit written by 'manygram systemd generate --per-profile' is removed.
+	`, new(removeCmd))
 }
 
 type removeCmd struct {
 	profileOption
-	Desktop bool `short:"d" long:"desktop" description:"Also remove the desktop entry"`
+	Desktop   bool `short:"d" long:"desktop" description:"Also remove the desktop entry"`
+	Force     bool `short:"f" long:"force" description:"Remove the profile even if it is running"`
+	Permanent bool `long:"permanent" description:"Remove the profile directory instead of moving it to the trash"`
 }
 
 func (c *removeCmd) Execute(args []string) error {
@@ -21,21 +34,167 @@ func (c *removeCmd) Execute(args []string) error {
 		return err
 	}
 	profileName := c.Profile.Name
-	if err = profile.Remove(conf.ProfileDir, profileName); err != nil {
+	prof, err := readProfile(conf.ProfileDir, profileName)
+	if err != nil {
+		return err
+	}
+	if !c.Force {
+		running, err := prof.IsRunning()
+		if err != nil {
+			return newError("Failed to check whether profile '%s' is running.", profileName, err)
+		}
+		if running {
+			return newRemoveRunningError(profileName)
+		}
+	}
+	operations, err := c.operations(prof)
+	if err != nil {
+		return err
+	}
+	if ok, err := confirmAction(fmt.Sprintf("Remove profile '%s'?", profileName), operations); !ok {
+		return err
+	}
+	if !c.Permanent {
+		hasAutostart, err := desktop.AutostartExist(getAutostartDir(), profileName)
+		if err != nil {
+			return err
+		}
+		if err := setAutostartFlag(prof, hasAutostart); err != nil {
+			return err
+		}
+	}
+	if c.Permanent {
+		err = profile.Remove(conf.ProfileDir, profileName, c.Force)
+	} else {
+		var trashDir string
+		if trashDir, err = getTrashDir(prof.Path); err != nil {
+			return err
+		}
+		err = profile.Trash(conf.ProfileDir, profileName, c.Force, trashDir)
+	}
+	if err != nil {
 		if errors.Is(err, profile.ErrInvalidName) {
 			return profileNameError(profileName)
 		}
 		if errors.Is(err, profile.ErrNotExist) {
 			return newError("Profile '%s' does not exist.", profileName)
 		}
+		if errors.Is(err, profile.ErrRunning) {
+			return newRemoveRunningError(profileName)
+		}
+		if !c.Permanent {
+			return newError(
+				"Failed to move profile '%s' to the trash. Use --permanent to remove it instead.", profileName, err,
+			)
+		}
 		return newError("Failed to remove profile '%s'.", profileName, err)
 	}
-	printMessage("Profile '%s' has been removed.", profileName)
+	if c.Permanent {
+		printMessage("Profile '%s' has been removed.", profileName)
+	} else {
+		printMessage("Profile '%s' has been moved to the trash. Use `manygram restore %[1]s` to restore it.", profileName)
+	}
+	removed, err := removeAutostartEntry(profileName)
+	if err != nil {
+		return err
+	}
+	if removed {
+		printMessage("Autostart entry for profile has been removed.")
+	}
+	removed, err = removeSystemdUnit(profileName)
+	if err != nil {
+		return err
+	}
+	if removed {
+		printMessage(
+			"Unit %s has been removed. Run `systemctl --user daemon-reload` to apply the change.",
+			systemd.UnitPath(getSystemdUnitsDir(), profileName),
+		)
+	}
+	if c.Permanent {
+		// logs are kept for the trashed profile until it is removed permanently
+		if err := removeProfileLogs(profileName); err != nil {
+			return err
+		}
+	}
 	if c.Desktop {
 		if err := removeDesktopEntry(profileName); err != nil {
 			return err
 		}
 		printMessage("Desktop entry for profile has been removed.")
 	}
-	return nil
+	// the kept desktop entry still refers to the icon, the combined entry is updated without the profile
+	hasDesktop, err := desktop.Exist(getDesktopEntriesDir(), profileName)
+	if err != nil {
+		return err
+	}
+	if !hasDesktop {
+		if err := removeProfileIcon(profileName); err != nil {
+			return err
+		}
+	}
+	return updateCombinedDesktopEntry(conf)
+}
+
+// operations describes what is removed with the profile
+func (c *removeCmd) operations(prof *profile.Profile) ([]string, error) {
+	size, err := prof.Size()
+	if err != nil {
+		return nil, newError("Failed to calculate size of profile '%s'.", prof.Name, err)
+	}
+	var operations []string
+	if c.Permanent {
+		operations = append(operations, fmt.Sprintf("remove profile directory %s (%s)", prof.Path, formatSize(size)))
+	} else {
+		trashDir, err := getTrashDir(prof.Path)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, fmt.Sprintf(
+			"move profile directory %s (%s) to the trash %s", prof.Path, formatSize(size), trashDir,
+		))
+	}
+	desktopEntriesDir := getDesktopEntriesDir()
+	hasDesktop, err := desktop.Exist(desktopEntriesDir, prof.Name)
+	if err != nil {
+		return nil, err
+	}
+	if hasDesktop && c.Desktop {
+		operations = append(operations, "remove desktop entry "+desktop.Path(desktopEntriesDir, prof.Name))
+	} else if hasDesktop {
+		operations = append(operations, "keep desktop entry "+desktop.Path(desktopEntriesDir, prof.Name))
+	}
+	autostartDir := getAutostartDir()
+	hasAutostart, err := desktop.AutostartExist(autostartDir, prof.Name)
+	if err != nil {
+		return nil, err
+	}
+	if hasAutostart {
+		operations = append(operations, "remove autostart entry "+desktop.AutostartPath(autostartDir, prof.Name))
+	}
+	unitsDir := getSystemdUnitsDir()
+	hasUnit, err := systemd.Exist(unitsDir, prof.Name)
+	if err != nil {
+		return nil, err
+	}
+	if hasUnit {
+		operations = append(operations, "remove unit "+systemd.UnitPath(unitsDir, prof.Name))
+	}
+	if c.Permanent {
+		logFiles, err := logfile.Files(getLogPath(prof.Name))
+		if err != nil {
+			return nil, err
+		}
+		for _, logFile := range logFiles {
+			operations = append(operations, "remove log file "+logFile)
+		}
+	}
+	return withCombinedUpdate(operations), nil
+}
+
+func newRemoveRunningError(profileName string) error {
+	return newError(
+		"Profile '%s' is running. Close Telegram Desktop first or use --force to remove it anyway.",
+		profileName,
+	)
 }
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/un-def/manygram/internal/desktop"
+	"github.com/un-def/manygram/internal/logfile"
+	"github.com/un-def/manygram/internal/profile"
+	"github.com/un-def/manygram/internal/systemd"
+)
+
+func init() {
+	parser.AddCommand("rename", "Rename the profile", "Rename the profile and its desktop entry, autostart entry and systemd unit.", new(renameCmd))
+}
+
+type renameCmd struct {
+	Args struct {
+		OldName string `description:"Current profile name" positional-arg-name:"OLD"`
+		NewName string `description:"New profile name" positional-arg-name:"NEW"`
+	} `positional-args:"true" required:"true"`
+}
+
+func (c *renameCmd) Execute(args []string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	oldName, newName := c.Args.OldName, c.Args.NewName
+	desktopEntriesDir := getDesktopEntriesDir()
+	hasDesktop, err := desktop.Exist(desktopEntriesDir, oldName)
+	if err != nil {
+		return err
+	}
+	hasAutostart, err := desktop.AutostartExist(getAutostartDir(), oldName)
+	if err != nil {
+		return err
+	}
+	hasUnit, err := systemd.Exist(getSystemdUnitsDir(), oldName)
+	if err != nil {
+		return err
+	}
+	// the entry of the new name is checked beforehand to never leave the renamed profile without the entry
+	if hasDesktop {
+		exist, err := desktop.Exist(desktopEntriesDir, newName)
+		if err != nil {
+			return err
+		}
+		if exist {
+			return newError("Desktop entry for profile '%s' already exists.", newName)
+		}
+	}
+	if options.DryRun {
+		return c.dryRun(conf.ProfileDir, hasDesktop, hasAutostart, hasUnit)
+	}
+	prof, err := profile.Rename(conf.ProfileDir, oldName, newName)
+	if err != nil {
+		return newRenameError(oldName, newName, err)
+	}
+	printMessage("Profile '%s' has been renamed to '%s'.", oldName, newName)
+	if hasDesktop {
+		if err := createDesktopEntry(conf, newName); err != nil {
+			return err
+		}
+		if err := removeDesktopEntry(oldName); err != nil {
+			return err
+		}
+		printMessage("Desktop entry for profile has been recreated.")
+	}
+	if hasAutostart {
+		if err := writeAutostartEntry(conf, prof); err != nil {
+			return err
+		}
+		if _, err := removeAutostartEntry(oldName); err != nil {
+			return err
+		}
+		printMessage("Autostart entry for profile has been recreated.")
+	}
+	if hasUnit {
+		execPath, err := getSystemdExecPath()
+		if err != nil {
+			return err
+		}
+		if err := writeSystemdUnit(execPath, prof); err != nil {
+			return err
+		}
+		if _, err := removeSystemdUnit(oldName); err != nil {
+			return err
+		}
+		printMessage(
+			"Unit %s has been replaced with %s. Run `systemctl --user daemon-reload` and enable the new unit if the old one was enabled.",
+			systemd.UnitName(oldName), systemd.UnitName(newName),
+		)
+	}
+	if err := renameProfileLogs(oldName, newName); err != nil {
+		return err
+	}
+	if err := removeProfileIcon(oldName); err != nil {
+		return err
+	}
+	return updateCombinedDesktopEntry(conf)
+}
+
+// dryRun checks the profiles and prints the operations of the rename
+func (c *renameCmd) dryRun(dir string, hasDesktop bool, hasAutostart bool, hasUnit bool) error {
+	oldName, newName := c.Args.OldName, c.Args.NewName
+	prof, err := profile.Read(dir, oldName)
+	if err == nil {
+		err = profile.CheckNew(dir, newName)
+	}
+	if err != nil {
+		return newRenameError(oldName, newName, err)
+	}
+	if err := checkNotRunning(prof); err != nil {
+		return err
+	}
+	operations := []string{fmt.Sprintf("rename profile directory %s to %s", prof.Path, profile.Path(dir, newName))}
+	if hasDesktop {
+		desktopEntriesDir := getDesktopEntriesDir()
+		operations = append(operations, fmt.Sprintf(
+			"replace desktop entry %s with %s",
+			desktop.Path(desktopEntriesDir, oldName), desktop.Path(desktopEntriesDir, newName),
+		))
+	}
+	if hasAutostart {
+		autostartDir := getAutostartDir()
+		operations = append(operations, fmt.Sprintf(
+			"replace autostart entry %s with %s",
+			desktop.AutostartPath(autostartDir, oldName), desktop.AutostartPath(autostartDir, newName),
+		))
+	}
+	if hasUnit {
+		unitsDir := getSystemdUnitsDir()
+		operations = append(operations, fmt.Sprintf(
+			"replace unit %s with %s", systemd.UnitPath(unitsDir, oldName), systemd.UnitPath(unitsDir, newName),
+		))
+	}
+	logFiles, err := logfile.Files(getLogPath(oldName))
+	if err != nil {
+		return err
+	}
+	if len(logFiles) > 0 {
+		operations = append(operations, fmt.Sprintf("rename log files %s to %s", getLogPath(oldName), getLogPath(newName)))
+	}
+	dryRun(withCombinedUpdate(operations)...)
+	return nil
+}
+
+func newRenameError(oldName string, newName string, err error) error {
+	if errors.Is(err, profile.ErrInvalidName) {
+		if profile.IsValidName(oldName) {
+			return profileNameError(newName)
+		}
+		return profileNameError(oldName)
+	}
+	if errors.Is(err, profile.ErrNotExist) {
+		return newError("Profile '%s' does not exist.", oldName)
+	}
+	if errors.Is(err, profile.ErrAlreadyExists) {
+		return newError("Profile '%s' already exists.", newName)
+	}
+	if errors.Is(err, profile.ErrRunning) {
+		return newError("Profile '%s' is running. Close Telegram Desktop first.", oldName)
+	}
+	return newError("Failed to rename profile '%s'.", oldName, err)
+}
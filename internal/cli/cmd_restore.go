@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/un-def/manygram/internal/config"
+	"github.com/un-def/manygram/internal/desktop"
+	"github.com/un-def/manygram/internal/profile"
+	"github.com/un-def/manygram/internal/trash"
+)
+
+func init() {
+	parser.AddCommand("restore", "Restore the removed profile", `
+		Restore the profile moved to the trash by 'manygram remove'.
+		The most recently removed profile with the name is restored
+		along with its desktop and autostart entries.
+	`, new(restoreCmd))
+}
+
+type restoreCmd struct {
+	profileOption
+}
+
+func (c *restoreCmd) Execute(args []string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	profileName := c.Profile.Name
+	if options.DryRun {
+		return c.dryRun(conf.ProfileDir)
+	}
+	trashDir, err := getTrashDir(profile.Path(conf.ProfileDir, profileName))
+	if err != nil {
+		return err
+	}
+	prof, err := profile.Restore(conf.ProfileDir, profileName, trashDir)
+	if err != nil {
+		return newRestoreError(profileName, err)
+	}
+	printMessage("Profile '%s' has been restored.", profileName)
+	profConf, err := readProfileConfig(prof)
+	if err != nil {
+		return err
+	}
+	if profConf.DesktopEntry {
+		if err := writeDesktopEntry(conf, prof); err != nil {
+			return err
+		}
+		printMessage("Desktop entry for profile has been created.")
+	}
+	if profConf.Autostart {
+		if err := writeAutostartEntry(conf, prof); err != nil {
+			return err
+		}
+		if err := setAutostartFlag(prof, false); err != nil {
+			return err
+		}
+		printMessage("Autostart entry for profile has been created.")
+	}
+	return updateCombinedDesktopEntry(conf)
+}
+
+// dryRun finds the profile in the trash and prints the operations of the restore
+func (c *restoreCmd) dryRun(dir string) error {
+	profileName := c.Profile.Name
+	trashDir, err := getTrashDir(profile.Path(dir, profileName))
+	if err != nil {
+		return err
+	}
+	err = profile.CheckNew(dir, profileName)
+	var item *trash.Item
+	if err == nil {
+		item, err = trash.Find(trashDir, profile.Path(dir, profileName))
+	}
+	if err != nil {
+		return newRestoreError(profileName, err)
+	}
+	trashedPath := trash.FilesPath(trashDir, item.Name)
+	operations := []string{fmt.Sprintf("move %s from the trash to profile directory %s", trashedPath, item.Path)}
+	profConf, err := config.ReadProfile(trashedPath)
+	if err != nil {
+		return newError("Failed to read profile config %s.", config.ProfileConfigPath(trashedPath), err)
+	}
+	if profConf.DesktopEntry {
+		operations = append(operations, "write desktop entry "+desktop.Path(getDesktopEntriesDir(), profileName))
+	}
+	if profConf.Autostart {
+		operations = append(operations, "write autostart entry "+desktop.AutostartPath(getAutostartDir(), profileName))
+	}
+	dryRun(withCombinedUpdate(operations)...)
+	return nil
+}
+
+func newRestoreError(profileName string, err error) error {
+	if errors.Is(err, profile.ErrInvalidName) {
+		return profileNameError(profileName)
+	}
+	if errors.Is(err, profile.ErrAlreadyExists) {
+		return newError("Profile '%s' already exists.", profileName)
+	}
+	if errors.Is(err, trash.ErrNotInTrash) {
+		return newError("Profile '%s' is not found in the trash.", profileName)
+	}
+	return newError("Failed to restore profile '%s'.", profileName, err)
+}
@@ -1,6 +1,14 @@
 package cli
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/un-def/manygram/internal/config"
+	"github.com/un-def/manygram/internal/logfile"
+	"github.com/un-def/manygram/internal/profile"
 	"github.com/un-def/manygram/internal/tg"
 )
 
@@ -14,7 +22,9 @@ func init() {
 
 type runCmd struct {
 	profileOption
-	Wait bool `short:"w" long:"wait" description:"Wait for child process to terminate"`
+	Wait      bool `short:"w" long:"wait" description:"Wait for child process to terminate"`
+	Supervise bool `short:"s" long:"supervise" description:"Wait for child process and restart it after crashes"`
+	supervisorOptions
 }
 
 func (c *runCmd) Execute(args []string) error {
@@ -22,14 +32,143 @@ func (c *runCmd) Execute(args []string) error {
 	if err != nil {
 		return err
 	}
-	telegram, err := tg.Executable(conf.ExecPath, conf.ExecArgs)
-	if err != nil {
-		return newError("Failed to locate Telegram Desktop executable. Check `exec-path` config parameter.", err)
-	}
 	profileName := c.Profile.Name
 	prof, err := readProfile(conf.ProfileDir, profileName)
 	if err != nil {
 		return err
 	}
-	return telegram.Run(prof.Path, args, c.Wait)
+	if c.Supervise {
+		return superviseProfiles(conf, []*profile.Profile{prof}, args, &c.supervisorOptions)
+	}
+	return runProfile(conf, prof, args, c.Wait)
+}
+
+// runProfile starts Telegram Desktop with the profile and remembers the profile as the last used one
+func runProfile(conf *config.Config, prof *profile.Profile, args []string, wait bool) error {
+	starter, err := newProfileStarter(conf, prof, args, wait)
+	if err != nil {
+		return err
+	}
+	defer starter.Close()
+	if dryRun(starter.operations()...) {
+		return nil
+	}
+	// an instance started for the already running profile passes control
+	// to the running one and exits, do not overwrite the pid file in that case
+	running, err := prof.IsRunning()
+	if err != nil {
+		return err
+	}
+	cmd, err := starter.Start()
+	if err != nil {
+		return err
+	}
+	if !running {
+		if err := prof.WritePid(cmd.Process.Pid); err != nil {
+			printMessage("Failed to write pid file: %v", err)
+		}
+	}
+	if !wait {
+		return nil
+	}
+	err = cmd.Wait()
+	if !running {
+		prof.RemovePid()
+	}
+	return err
+}
+
+// profileStarter starts Telegram Desktop with the profile,
+// the output of the process is passed through if wait is true and is written to the log if enabled
+type profileStarter struct {
+	conf      *config.Config
+	prof      *profile.Profile
+	args      []string
+	wait      bool
+	telegram  *tg.TelegramDesktop
+	opts      *tg.Options
+	logWriter *logfile.Writer
+}
+
+func newProfileStarter(conf *config.Config, prof *profile.Profile, args []string, wait bool) (*profileStarter, error) {
+	profConf, err := readProfileConfig(prof)
+	if err != nil {
+		return nil, err
+	}
+	conf = conf.Merge(profConf)
+	telegram, err := tg.Executable(conf.ExecPath, conf.ExecArgs)
+	if err != nil {
+		return nil, newError("Failed to locate Telegram Desktop executable. Check `exec-path` config parameter.", err)
+	}
+	s := &profileStarter{
+		conf:     conf,
+		prof:     prof,
+		args:     args,
+		wait:     wait,
+		telegram: telegram,
+		opts: &tg.Options{
+			Env:      conf.Env,
+			UnsetEnv: conf.UnsetEnv,
+			WMClass:  getWMClass(prof, profConf),
+		},
+	}
+	if wait {
+		s.opts.Stdout = os.Stdout
+		s.opts.Stderr = os.Stderr
+	}
+	return s, nil
+}
+
+// operations describes what Start() does for the dry-run mode
+func (s *profileStarter) operations() []string {
+	operations := []string{fmt.Sprintf(
+		"start %s with profile directory %s", s.telegram.Path, s.prof.Path,
+	)}
+	if s.conf.Log {
+		operations = append(operations, "write output to log file "+getLogPath(s.prof.Name))
+	}
+	return append(operations, "save last used profile to "+getLastProfilePath())
+}
+
+// Start starts Telegram Desktop, it is called for every restart of the supervised profile
+func (s *profileStarter) Start() (*exec.Cmd, error) {
+	if s.conf.Log && s.wait && s.logWriter == nil {
+		// the log is rotated while the output is passed through the current process
+		logWriter, err := openProfileLog(s.conf, s.prof.Name)
+		if err != nil {
+			return nil, err
+		}
+		s.logWriter = logWriter
+		s.opts.Stdout = io.MultiWriter(os.Stdout, s.logWriter)
+		s.opts.Stderr = io.MultiWriter(os.Stderr, s.logWriter)
+	}
+	opts := s.opts
+	if s.conf.Log && !s.wait {
+		// the detached process outlives the current one, so its output is rotated by `manygram log-writer`
+		logPipe, err := startLogWriter(s.conf, s.prof.Name)
+		if err != nil {
+			return nil, err
+		}
+		defer logPipe.Close()
+		withPipe := *s.opts
+		withPipe.Stdout = logPipe
+		withPipe.Stderr = logPipe
+		opts = &withPipe
+	}
+	cmd, err := s.telegram.Start(s.prof.Path, s.args, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeLastProfile(s.prof.Name); err != nil {
+		printMessage("Failed to save last used profile: %v", err)
+	}
+	return cmd, nil
+}
+
+// Close closes the log opened in the wait mode
+func (s *profileStarter) Close() error {
+	if s.logWriter == nil {
+		return nil
+	}
+	return s.logWriter.Close()
 }
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/un-def/manygram/internal/profile"
+)
+
+func init() {
+	parser.AddCommand("stop", "Stop Telegram Desktop", `
+		Stop Telegram Desktop running with specified profile.
+		SIGTERM is sent to the processes using the profile directory.
+		Use --kill to send SIGKILL to the processes still running after the timeout.
+	`, new(stopCmd))
+	parser.AddCommand("kill", "Kill Telegram Desktop", `
+		Kill Telegram Desktop running with specified profile.
+		SIGKILL is sent to the processes using the profile directory.
+	`, new(killCmd))
+}
+
+type stopCmd struct {
+	optionalProfileOption
+	All     bool          `short:"a" long:"all" description:"Stop all profiles"`
+	Timeout time.Duration `short:"t" long:"timeout" default:"10s" description:"Time to wait for processes to exit"`
+	Kill    bool          `short:"k" long:"kill" description:"Kill processes still running after the timeout"`
+}
+
+func (c *stopCmd) Execute(args []string) error {
+	signal := "SIGTERM"
+	if c.Kill {
+		signal = fmt.Sprintf("SIGTERM (SIGKILL after %s)", c.Timeout)
+	}
+	return stopProfiles(c.Profile.Name, c.All, signal, func(prof *profile.Profile) ([]int, []int, error) {
+		return prof.Stop(c.Timeout, c.Kill)
+	})
+}
+
+type killCmd struct {
+	optionalProfileOption
+	All bool `short:"a" long:"all" description:"Kill all profiles"`
+}
+
+func (c *killCmd) Execute(args []string) error {
+	return stopProfiles(c.Profile.Name, c.All, "SIGKILL", func(prof *profile.Profile) ([]int, []int, error) {
+		return prof.Kill()
+	})
+}
+
+// stopProfiles stops the profiles with the stop function, the signal is only used to describe the operation in dry-run mode
+func stopProfiles(profileName string, all bool, signal string, stop func(*profile.Profile) ([]int, []int, error)) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	profiles, err := readProfiles(conf.ProfileDir, profileName, all)
+	if err != nil {
+		return err
+	}
+	if options.DryRun {
+		return dryRunStop(profiles, all, signal)
+	}
+	var stopped int
+	var failed []string
+	for _, prof := range profiles {
+		pids, alive, err := stop(prof)
+		if err != nil {
+			return newError("Failed to stop profile '%s'.", prof.Name, err)
+		}
+		if len(pids) == 0 {
+			if !all {
+				printMessage("Profile '%s' is not running.", prof.Name)
+			}
+			continue
+		}
+		if len(alive) > 0 {
+			printMessage("Profile '%s' is still running (PID %s).", prof.Name, formatPids(alive))
+			failed = append(failed, prof.Name)
+			continue
+		}
+		printMessage("Profile '%s' has been stopped (PID %s).", prof.Name, formatPids(pids))
+		stopped++
+	}
+	if all && stopped == 0 && len(failed) == 0 {
+		printMessage("No running profiles found.")
+	}
+	if len(failed) > 0 {
+		return newError("Failed to stop %d profile(s). Use `manygram stop --kill` or `manygram kill`.", len(failed))
+	}
+	return nil
+}
+
+func dryRunStop(profiles []*profile.Profile, all bool, signal string) error {
+	var operations []string
+	for _, prof := range profiles {
+		pids, err := prof.Pids()
+		if err != nil {
+			return newError("Failed to find processes of profile '%s'.", prof.Name, err)
+		}
+		if len(pids) == 0 {
+			if !all {
+				printMessage("Profile '%s' is not running.", prof.Name)
+			}
+			continue
+		}
+		operations = append(operations, fmt.Sprintf(
+			"send %s to profile '%s' (PID %s)", signal, prof.Name, formatPids(pids),
+		))
+	}
+	if all && len(operations) == 0 {
+		printMessage("No running profiles found.")
+	}
+	dryRun(operations...)
+	return nil
+}
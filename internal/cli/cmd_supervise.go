@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/un-def/manygram/internal/config"
+	"github.com/un-def/manygram/internal/profile"
+	"github.com/un-def/manygram/internal/supervisor"
+)
+
+func init() {
+	parser.AddCommand("supervise", "Run Telegram Desktop and restart it after crashes", `
+		Run Telegram Desktop with specified profiles and restart it when it exits
+		with non-zero status or is killed by a signal. The delay between restarts
+		is doubled after every crash. Telegram Desktop terminated with SIGTERM,
+		SIGINT or SIGKILL (e.g. by 'manygram stop' or 'manygram kill') is not restarted.
+		Any additional arguments after double dash delimiter '--'
+		will be passed to Telegram Desktop executable.
+	`, new(superviseCmd))
+}
+
+type supervisorOptions struct {
+	MaxCrashes int           `long:"max-crashes" default:"5" description:"Give up after this number of crashes in a row, 0 means never" value-name:"N"`
+	MaxDelay   time.Duration `long:"max-delay" default:"5m" description:"Maximum delay between restarts" value-name:"DURATION"`
+}
+
+type superviseCmd struct {
+	Args struct {
+		Profiles []string `description:"Profile names" positional-arg-name:"PROFILE"`
+	} `positional-args:"true" required:"true"`
+	supervisorOptions
+}
+
+func (c *superviseCmd) Execute(args []string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	profiles := make([]*profile.Profile, len(c.Args.Profiles))
+	for idx, name := range c.Args.Profiles {
+		if profiles[idx], err = readProfile(conf.ProfileDir, name); err != nil {
+			return err
+		}
+	}
+	return superviseProfiles(conf, profiles, args, &c.supervisorOptions)
+}
+
+// superviseProfiles runs the profiles concurrently restarting crashed processes
+// until all of them exit or SIGINT/SIGTERM is received
+func superviseProfiles(
+	conf *config.Config, profiles []*profile.Profile, args []string, opts *supervisorOptions,
+) error {
+	// two supervisors of the same profile would run two instances with the same workdir
+	seen := make(map[string]bool, len(profiles))
+	var unique []*profile.Profile
+	for _, prof := range profiles {
+		if !seen[prof.Path] {
+			seen[prof.Path] = true
+			unique = append(unique, prof)
+		}
+	}
+	profiles = unique
+	supervisors := make([]*supervisor.Supervisor, len(profiles))
+	var operations []string
+	for idx, prof := range profiles {
+		running, err := prof.IsRunning()
+		if err != nil {
+			return newError("Failed to check whether profile '%s' is running.", prof.Name, err)
+		}
+		if running {
+			return newError("Profile '%s' is already running. Use `manygram stop %[1]s` first.", prof.Name)
+		}
+		starter, err := newProfileStarter(conf, prof, args, true)
+		if err != nil {
+			return err
+		}
+		defer starter.Close()
+		operations = append(operations, starter.operations()...)
+		supervisors[idx] = newProfileSupervisor(prof, starter, opts)
+	}
+	if dryRun(operations...) {
+		return nil
+	}
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		if _, ok := <-signals; ok {
+			close(stop)
+		}
+	}()
+	errs := make(chan error, len(profiles))
+	for idx, prof := range profiles {
+		go func(prof *profile.Profile, sup *supervisor.Supervisor) {
+			err := sup.Run(stop)
+			prof.RemovePid()
+			if errors.Is(err, supervisor.ErrTooManyCrashes) {
+				err = newError("Profile '%s' has crashed %d times in a row. Giving up.", prof.Name, opts.MaxCrashes)
+			} else if err != nil {
+				err = newError("Failed to run profile '%s'.", prof.Name, err)
+			}
+			errs <- err
+		}(prof, supervisors[idx])
+	}
+	var failed []error
+	for range profiles {
+		if err := <-errs; err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(profiles) == 1 && len(failed) == 1 {
+		return failed[0]
+	}
+	for _, err := range failed {
+		printMessage("%v", err)
+	}
+	if len(failed) > 0 {
+		return newError("%d of %d profile(s) have failed.", len(failed), len(profiles))
+	}
+	return nil
+}
+
+func newProfileSupervisor(
+	prof *profile.Profile, starter *profileStarter, opts *supervisorOptions,
+) *supervisor.Supervisor {
+	sup := supervisor.New(func() (*exec.Cmd, error) {
+		cmd, err := starter.Start()
+		if err != nil {
+			return nil, err
+		}
+		if err := prof.WritePid(cmd.Process.Pid); err != nil {
+			printMessage("Failed to write pid file: %v", err)
+		}
+		return cmd, nil
+	})
+	sup.MaxCrashes = opts.MaxCrashes
+	sup.MaxDelay = opts.MaxDelay
+	sup.Logf = func(format string, args ...interface{}) {
+		printMessage("Profile '%s': "+format, append([]interface{}{prof.Name}, args...)...)
+	}
+	return sup
+}
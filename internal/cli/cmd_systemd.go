@@ -0,0 +1,14 @@
+package cli
+
+import "github.com/jessevdk/go-flags"
+
+var systemdCommand *flags.Command
+
+func init() {
+	systemdCommand, _ = parser.AddCommand(
+		"systemd", "systemd user units subcommands", "systemd user units subcommands.",
+		new(systemdCmd),
+	)
+}
+
+type systemdCmd struct{}
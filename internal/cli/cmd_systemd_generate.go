@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/un-def/manygram/internal/systemd"
+)
+
+func init() {
+	systemdCommand.AddCommand("generate", "Generate systemd user units", `
+		Generate systemd user units running 'manygram run --wait PROFILE'
+		and restarting Telegram Desktop on failure.
+		The manygram@.service template unit is written to $XDG_CONFIG_HOME/systemd/user
+		unless --per-profile is specified. Units run manygram by the absolute path
+		of its executable because systemd does not search it in $PATH.
+	`, new(systemdGenerateCmd))
+}
+
+type systemdGenerateCmd struct {
+	optionalProfileOption
+	All        bool `short:"a" long:"all" description:"Generate units for all profiles"`
+	PerProfile bool `short:"p" long:"per-profile" description:"Write manygram-PROFILE.service unit per profile instead of the template unit"`
+}
+
+func (c *systemdGenerateCmd) Execute(args []string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	profiles, err := readProfiles(conf.ProfileDir, c.Profile.Name, c.All)
+	if err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		return newError("There are no profiles. Use `manygram create PROFILE` to create a new one.")
+	}
+	execPath, err := getSystemdExecPath()
+	if err != nil {
+		return err
+	}
+	dir := getSystemdUnitsDir()
+	if c.PerProfile {
+		operations := make([]string, len(profiles))
+		for idx, prof := range profiles {
+			operations[idx] = "write unit " + systemd.UnitPath(dir, prof.Name)
+		}
+		if dryRun(operations...) {
+			return nil
+		}
+	} else if dryRun("write template unit " + systemd.TemplatePath(dir)) {
+		return nil
+	}
+	units := make([]string, len(profiles))
+	if !c.PerProfile {
+		if err := systemd.CreateTemplate(dir, execPath); err != nil {
+			return newError("Failed to write template unit %s.", systemd.TemplatePath(dir), err)
+		}
+		printMessage("Template unit %s has been written.", systemd.TemplatePath(dir))
+		for idx, prof := range profiles {
+			units[idx] = systemd.InstanceName(prof.Name)
+		}
+	} else {
+		for idx, prof := range profiles {
+			if err := writeSystemdUnit(execPath, prof); err != nil {
+				return err
+			}
+			printMessage("Unit %s has been written.", systemd.UnitPath(dir, prof.Name))
+			units[idx] = systemd.UnitName(prof.Name)
+		}
+	}
+	printMessage("Run the following commands to start Telegram Desktop now and at login:")
+	printMessage("    systemctl --user daemon-reload")
+	printMessage("    systemctl --user enable --now %s", strings.Join(units, " "))
+	return nil
+}
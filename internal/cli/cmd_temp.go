@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path"
+	"syscall"
+
+	"github.com/un-def/manygram/internal/config"
+	"github.com/un-def/manygram/internal/profile"
+	"github.com/un-def/manygram/internal/tg"
+	"github.com/un-def/manygram/internal/util"
+)
+
+func init() {
+	parser.AddCommand("temp", "Run Telegram Desktop with a temporary profile", `
+		Create a temporary profile in the 'ephemeral-dir' directory
+		($XDG_RUNTIME_DIR/manygram by default), run Telegram Desktop with it
+		and wait for the process to terminate. The profile is removed when
+		Telegram Desktop exits or manygram is interrupted. The directory must be
+		owned by the current user and have 0700 mode, it is not used otherwise.
+		Any additional arguments after double dash delimiter '--'
+		will be passed to Telegram Desktop executable.
+	`, new(tempCmd))
+}
+
+type tempCmd struct{}
+
+func (c *tempCmd) Execute(args []string) error {
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+	telegram, err := tg.Executable(conf.ExecPath, conf.ExecArgs)
+	if err != nil {
+		return newError("Failed to locate Telegram Desktop executable. Check `exec-path` config parameter.", err)
+	}
+	dir := getEphemeralDir(conf)
+	if dryRun(
+		"create temporary profile in "+dir,
+		"remove the temporary profile when Telegram Desktop exits",
+	) {
+		return nil
+	}
+	// the directory in the shared temporary directory may have been created by another user
+	if err := util.MkdirPrivate(dir); errors.Is(err, util.ErrNotPrivate) {
+		return newError("Refusing to create temporary profile in %s.", dir, err)
+	} else if err != nil {
+		return newError("Failed to create directory %s.", dir, err)
+	}
+	// the random suffix of the directory is made of digits, so the name is a valid profile name
+	profilePath, err := ioutil.TempDir(dir, "temp")
+	if err != nil {
+		return newError("Failed to create temporary profile in %s.", dir, err)
+	}
+	prof := &profile.Profile{Dir: dir, Name: path.Base(profilePath), Path: profilePath}
+	printMessage("Temporary profile '%s' has been created in %s.", prof.Name, dir)
+	runErr := runTempProfile(conf, telegram, prof, args)
+	if err := os.RemoveAll(prof.Path); err != nil {
+		return newError("Failed to remove temporary profile %s.", prof.Path, err)
+	}
+	printMessage("Temporary profile '%s' has been removed.", prof.Name)
+	return runErr
+}
+
+// runTempProfile runs Telegram Desktop with the temporary profile and waits for it,
+// SIGINT and SIGTERM are forwarded to Telegram Desktop to let the profile be removed
+func runTempProfile(conf *config.Config, telegram *tg.TelegramDesktop, prof *profile.Profile, args []string) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	cmd, err := telegram.Start(prof.Path, args, &tg.Options{
+		Env:      conf.Env,
+		UnsetEnv: conf.UnsetEnv,
+		WMClass:  getWMClass(prof, new(config.ProfileConfig)),
+		Stdout:   os.Stdout,
+		Stderr:   os.Stderr,
+	})
+	if err != nil {
+		return newError("Failed to start Telegram Desktop.", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	interrupted := false
+	for {
+		select {
+		case err := <-done:
+			if interrupted {
+				return nil
+			}
+			return err
+		case <-signals:
+			interrupted = true
+			cmd.Process.Signal(syscall.SIGTERM)
+		}
+	}
+}
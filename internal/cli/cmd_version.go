@@ -7,6 +7,9 @@ func init() {
 type versionCmd struct{}
 
 func (c *versionCmd) Execute(args []string) error {
+	if !isTextOutput() {
+		return printRecord(record{{"version", manygramVersion}})
+	}
 	printMessage("manygram %s", manygramVersion)
 	return nil
 }
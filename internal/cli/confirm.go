@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/un-def/manygram/internal/desktop"
+)
+
+// confirmAction asks the user to confirm the operations if stdin is a TTY unless --yes is specified,
+// false is returned along with nil error if the operations are only printed because of --dry-run
+func confirmAction(question string, operations []string) (bool, error) {
+	if dryRun(operations...) {
+		return false, nil
+	}
+	if options.Yes || !isTerminal(os.Stdin) {
+		return true, nil
+	}
+	for _, operation := range operations {
+		fmt.Fprintf(os.Stderr, "* %s%s\n", strings.ToUpper(operation[:1]), operation[1:])
+	}
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		fmt.Fprintln(os.Stderr)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, newError("Aborted.")
+}
+
+// dryRun prints the operations and returns true if --dry-run is specified
+func dryRun(operations ...string) bool {
+	if !options.DryRun {
+		return false
+	}
+	for _, operation := range operations {
+		printMessage("Would %s.", operation)
+	}
+	return true
+}
+
+// withCombinedUpdate appends the update of the combined desktop entry to the operations if the entry exists
+func withCombinedUpdate(operations []string) []string {
+	dir := getDesktopEntriesDir()
+	if exist, err := desktop.CombinedExist(dir); err == nil && exist {
+		operations = append(operations, "update combined desktop entry "+desktop.CombinedPath(dir))
+	}
+	return operations
+}
@@ -3,12 +3,21 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
+	"strconv"
+	"strings"
 
+	"github.com/un-def/manygram/internal/backup"
 	"github.com/un-def/manygram/internal/config"
 	"github.com/un-def/manygram/internal/desktop"
+	"github.com/un-def/manygram/internal/icon"
+	"github.com/un-def/manygram/internal/logfile"
 	"github.com/un-def/manygram/internal/profile"
+	"github.com/un-def/manygram/internal/systemd"
+	"github.com/un-def/manygram/internal/tg"
+	"github.com/un-def/manygram/internal/trash"
 	"github.com/un-def/manygram/internal/xdg"
 )
 
@@ -20,9 +29,169 @@ func getDesktopEntriesDir() string {
 	return path.Join(xdg.GetDataHome(), "applications")
 }
 
+func getIconsDir() string {
+	return path.Join(xdg.GetDataHome(), "icons")
+}
+
+func getAutostartDir() string {
+	return path.Join(xdg.GetConfigHome(), "autostart")
+}
+
+func getSystemdUnitsDir() string {
+	return path.Join(xdg.GetConfigHome(), "systemd", "user")
+}
+
+// getBackupDir returns the directory containing profile backups
+func getBackupDir(conf *config.Config) string {
+	if conf.BackupDir != "" {
+		return conf.BackupDir
+	}
+	return path.Join(xdg.GetDataHome(), "manygram", "backups")
+}
+
+// getBackupPolicy returns the configured retention policy of backups,
+// every parameter that is not set falls back to its default value, 0 disables the rule
+func getBackupPolicy(conf *config.Config) *backup.Policy {
+	policy := backup.DefaultPolicy
+	if conf.BackupKeepLast != nil {
+		policy.KeepLast = *conf.BackupKeepLast
+	}
+	if conf.BackupKeepDaily != nil {
+		policy.KeepDaily = *conf.BackupKeepDaily
+	}
+	if conf.BackupKeepWeekly != nil {
+		policy.KeepWeekly = *conf.BackupKeepWeekly
+	}
+	return &policy
+}
+
+// getTrashDir returns the trash directory for the profile directory,
+// $topdir/.Trash-$uid is used if the profile directory is not on the filesystem of the home trash
+func getTrashDir(profilePath string) (string, error) {
+	homeTrash := path.Join(xdg.GetDataHome(), "Trash")
+	trashDir, err := trash.Dir(homeTrash, profilePath)
+	if err != nil {
+		return "", newError("Failed to determine trash directory for %s.", profilePath, err)
+	}
+	return trashDir, nil
+}
+
+func getStateDir() string {
+	return path.Join(xdg.GetStateHome(), "manygram")
+}
+
+// getLogLimits returns the configured rotation parameters of logs falling back to the defaults
+func getLogLimits(conf *config.Config) (int64, int) {
+	maxSize, maxFiles := conf.LogMaxSize, conf.LogMaxFiles
+	if maxSize == 0 {
+		maxSize = logfile.DefaultMaxSize
+	}
+	if maxFiles == 0 {
+		maxFiles = logfile.DefaultMaxFiles
+	}
+	return maxSize, maxFiles
+}
+
+// getEphemeralDir returns the directory of temporary profiles
+func getEphemeralDir(conf *config.Config) string {
+	if conf.EphemeralDir != "" {
+		return conf.EphemeralDir
+	}
+	return path.Join(xdg.GetRuntimeDir(), "manygram")
+}
+
+func getLastProfilePath() string {
+	return path.Join(getStateDir(), "last-profile")
+}
+
+func getLogPath(profileName string) string {
+	return path.Join(getStateDir(), "logs", profileName+".log")
+}
+
+func openProfileLog(conf *config.Config, profileName string) (*logfile.Writer, error) {
+	maxSize, maxFiles := getLogLimits(conf)
+	logPath := getLogPath(profileName)
+	writer, err := logfile.Open(logPath, maxSize, maxFiles)
+	if err != nil {
+		return nil, newError("Failed to open log file %s.", logPath, err)
+	}
+	return writer, nil
+}
+
+// renameProfileLogs renames the log file and rotated files of the profile
+func renameProfileLogs(oldName string, newName string) error {
+	oldPath, newPath := getLogPath(oldName), getLogPath(newName)
+	files, err := logfile.Files(oldPath)
+	if err != nil {
+		return newError("Failed to rename log files of profile '%s'.", oldName, err)
+	}
+	for _, file := range files {
+		if err := os.Rename(file, newPath+strings.TrimPrefix(file, oldPath)); err != nil {
+			return newError("Failed to rename log files of profile '%s'.", oldName, err)
+		}
+	}
+	return nil
+}
+
+// removeProfileLogs removes the log file and rotated files of the profile
+func removeProfileLogs(profileName string) error {
+	files, err := logfile.Files(getLogPath(profileName))
+	if err != nil {
+		return newError("Failed to remove log files of profile '%s'.", profileName, err)
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return newError("Failed to remove log files of profile '%s'.", profileName, err)
+		}
+	}
+	return nil
+}
+
+// readLastProfile returns the name of the last run profile or an empty string if it is unknown
+func readLastProfile() string {
+	content, err := ioutil.ReadFile(getLastProfilePath())
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+func writeLastProfile(name string) error {
+	if err := os.MkdirAll(getStateDir(), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(getLastProfilePath(), []byte(name+"\n"), 0644)
+}
+
+// printMessage prints a human-readable message, the message goes to stderr
+// if a structured output format is selected to keep stdout machine-readable
 func printMessage(format string, args ...interface{}) {
-	fmt.Fprintf(os.Stdout, format, args...)
-	fmt.Fprint(os.Stdout, "\n")
+	out := os.Stdout
+	if !isTextOutput() {
+		out = os.Stderr
+	}
+	fmt.Fprintf(out, format, args...)
+	fmt.Fprint(out, "\n")
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func formatBool(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
 }
 
 func getConfigPath() string {
@@ -61,6 +230,66 @@ func readProfile(dir string, name string) (*profile.Profile, error) {
 	return prof, nil
 }
 
+func detectFlavor(conf *config.Config) string {
+	telegram, err := tg.Executable(conf.ExecPath, conf.ExecArgs)
+	if err != nil {
+		if telegram, err = tg.Flatpak(); err != nil {
+			return "unknown"
+		}
+	}
+	return telegram.Flavor()
+}
+
+func readProfileConfig(prof *profile.Profile) (*config.ProfileConfig, error) {
+	profConf, err := config.ReadProfile(prof.Path)
+	if err != nil {
+		return nil, newError("Failed to read profile config %s", config.ProfileConfigPath(prof.Path), err)
+	}
+	return profConf, nil
+}
+
+// readProfiles returns the named profile or all profiles if all is true
+func readProfiles(dir string, name string, all bool) ([]*profile.Profile, error) {
+	if all == (name != "") {
+		return nil, newError("Specify either a profile name or --all.")
+	}
+	if !all {
+		prof, err := readProfile(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		return []*profile.Profile{prof}, nil
+	}
+	return listProfiles(dir)
+}
+
+// listProfiles returns all profiles, the missing profile directory means there are no profiles
+func listProfiles(dir string) ([]*profile.Profile, error) {
+	profiles, err := profile.List(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, newError("Failed to list profiles in %s.", dir, err)
+	}
+	return profiles, nil
+}
+
+func countTrue(values ...bool) int {
+	count := 0
+	for _, value := range values {
+		if value {
+			count++
+		}
+	}
+	return count
+}
+
+func formatPids(pids []int) string {
+	strs := make([]string, len(pids))
+	for idx, pid := range pids {
+		strs[idx] = strconv.Itoa(pid)
+	}
+	return strings.Join(strs, ", ")
+}
+
 func createDesktopEntry(conf *config.Config, profileName string) error {
 	dir := getDesktopEntriesDir()
 	exist, err := desktop.Exist(dir, profileName)
@@ -76,12 +305,310 @@ func createDesktopEntry(conf *config.Config, profileName string) error {
 			return err
 		}
 	}
-	_, err = readProfile(conf.ProfileDir, profileName)
+	prof, err := readProfile(conf.ProfileDir, profileName)
+	if err != nil {
+		return err
+	}
+	if err := setDesktopEntryFlag(prof, true); err != nil {
+		return err
+	}
+	return writeDesktopEntry(conf, prof)
+}
+
+// setDesktopEntryFlag stores whether the profile has the desktop entry,
+// `desktop sync` creates missing entries of flagged profiles
+func setDesktopEntryFlag(prof *profile.Profile, value bool) error {
+	profConf, err := readProfileConfig(prof)
+	if err != nil {
+		return err
+	}
+	if profConf.DesktopEntry == value {
+		return nil
+	}
+	profConf.DesktopEntry = value
+	if err := profConf.Write(); err != nil {
+		return newError("Failed to write profile config %s", config.ProfileConfigPath(prof.Path), err)
+	}
+	return nil
+}
+
+// setAutostartFlag stores whether the profile had the autostart entry when it was moved to the trash,
+// `restore` recreates the entry of flagged profiles
+func setAutostartFlag(prof *profile.Profile, value bool) error {
+	profConf, err := readProfileConfig(prof)
+	if err != nil {
+		return err
+	}
+	if profConf.Autostart == value {
+		return nil
+	}
+	profConf.Autostart = value
+	if err := profConf.Write(); err != nil {
+		return newError("Failed to write profile config %s", config.ProfileConfigPath(prof.Path), err)
+	}
+	return nil
+}
+
+// writeDesktopEntry creates or rewrites the desktop entry using the profile metadata
+func writeDesktopEntry(conf *config.Config, prof *profile.Profile) error {
+	execPath, err := getDesktopExecPath(conf)
+	if err != nil {
+		return err
+	}
+	profConf, err := readProfileConfig(prof)
+	if err != nil {
+		return err
+	}
+	if err := writeProfileIcon(prof, profConf); err != nil {
+		return err
+	}
+	if err := desktop.Create(getDesktopEntriesDir(), newDesktopEntry(execPath, prof, profConf)); err != nil {
+		return newError("Failed to create desktop entry for profile '%s'", prof.Name, err)
+	}
+	return nil
+}
+
+// getDesktopExecPath returns the manygram executable path used in desktop entries
+func getDesktopExecPath(conf *config.Config) (string, error) {
+	if conf.DesktopExec == config.DesktopExecPath {
+		return "manygram", nil
+	}
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", newError(
+			"Failed to determine manygram executable path. Set `desktop-exec = \"%s\"` in the config to look it up in PATH.",
+			config.DesktopExecPath, err,
+		)
+	}
+	return execPath, nil
+}
+
+// getSystemdExecPath returns the absolute path of the manygram executable,
+// `desktop-exec` is ignored since systemd user units use a fixed search path
+func getSystemdExecPath() (string, error) {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", newError("Failed to determine manygram executable path.", err)
+	}
+	return execPath, nil
+}
+
+// writeSystemdUnit writes manygram-PROFILE.service unit running the profile
+func writeSystemdUnit(execPath string, prof *profile.Profile) error {
+	profConf, err := readProfileConfig(prof)
+	if err != nil {
+		return err
+	}
+	description := ""
+	if profConf.DisplayName != "" {
+		description = fmt.Sprintf("Telegram Desktop (%s)", profConf.DisplayName)
+	}
+	dir := getSystemdUnitsDir()
+	if err := systemd.CreateUnit(dir, prof.Name, description, execPath); err != nil {
+		return newError("Failed to write unit %s.", systemd.UnitPath(dir, prof.Name), err)
+	}
+	return nil
+}
+
+// removeSystemdUnit removes manygram-PROFILE.service unit if it exists
+func removeSystemdUnit(profileName string) (bool, error) {
+	dir := getSystemdUnitsDir()
+	err := systemd.Remove(dir, profileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, newError("Failed to remove unit %s.", systemd.UnitPath(dir, profileName), err)
+	}
+	return true, nil
+}
+
+func newDesktopEntry(execPath string, prof *profile.Profile, profConf *config.ProfileConfig) *desktop.Entry {
+	entry := &desktop.Entry{
+		Profile: prof.Name,
+		Name:    profConf.DisplayName,
+		Comment: profConf.Description,
+		Icon:    profConf.Icon,
+		TryExec: execPath,
+		Exec:    desktop.ExecLine(execPath, "run", prof.Name),
+		WMClass: getWMClass(prof, profConf),
+	}
+	if profConf.Icon == "" {
+		entry.Icon = icon.Name(prof.Name)
+	}
+	return entry
+}
+
+// newCombinedDesktopEntries builds desktop entries of all profiles for the combined desktop entry
+func newCombinedDesktopEntries(conf *config.Config) ([]*desktop.Entry, error) {
+	execPath, err := getDesktopExecPath(conf)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := listProfiles(conf.ProfileDir)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*desktop.Entry, len(profiles))
+	for idx, prof := range profiles {
+		profConf, err := readProfileConfig(prof)
+		if err != nil {
+			return nil, err
+		}
+		entries[idx] = newDesktopEntry(execPath, prof, profConf)
+	}
+	return entries, nil
+}
+
+// writeCombinedDesktopEntry creates or rewrites the combined desktop entry
+// with an action for every profile
+func writeCombinedDesktopEntry(conf *config.Config) error {
+	execPath, err := getDesktopExecPath(conf)
+	if err != nil {
+		return err
+	}
+	profiles, err := listProfiles(conf.ProfileDir)
+	if err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		return newError("There are no profiles. Use `manygram create PROFILE` to create a new one.")
+	}
+	entries := make([]*desktop.Entry, len(profiles))
+	for idx, prof := range profiles {
+		profConf, err := readProfileConfig(prof)
+		if err != nil {
+			return err
+		}
+		if err := writeProfileIcon(prof, profConf); err != nil {
+			return err
+		}
+		entries[idx] = newDesktopEntry(execPath, prof, profConf)
+	}
+	if err := desktop.CreateCombined(getDesktopEntriesDir(), entries); err != nil {
+		return newError("Failed to create combined desktop entry.", err)
+	}
+	return nil
+}
+
+func newAutostartEntry(execPath string, prof *profile.Profile, profConf *config.ProfileConfig) *desktop.Entry {
+	entry := newDesktopEntry(execPath, prof, profConf)
+	entry.Exec = desktop.ExecLine(execPath, "run", prof.Name, "--", "-autostart", "-startintray")
+	return entry
+}
+
+// writeAutostartEntry creates or rewrites the autostart entry starting Telegram Desktop minimized to tray
+func writeAutostartEntry(conf *config.Config, prof *profile.Profile) error {
+	execPath, err := getDesktopExecPath(conf)
+	if err != nil {
+		return err
+	}
+	profConf, err := readProfileConfig(prof)
+	if err != nil {
+		return err
+	}
+	if err := writeProfileIcon(prof, profConf); err != nil {
+		return err
+	}
+	if err := desktop.CreateAutostart(getAutostartDir(), newAutostartEntry(execPath, prof, profConf)); err != nil {
+		return newError("Failed to create autostart entry for profile '%s'.", prof.Name, err)
+	}
+	return nil
+}
+
+// removeAutostartEntry removes the autostart entry of the profile if it exists
+func removeAutostartEntry(profileName string) (bool, error) {
+	err := desktop.RemoveAutostart(getAutostartDir(), profileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, newError("Failed to remove autostart entry for profile '%s'.", profileName, err)
+	}
+	return true, nil
+}
+
+// isProfileIconInUse checks whether the generated icon of the profile is used
+// by the desktop entry, the combined entry or the autostart entry
+func isProfileIconInUse(profileName string) (bool, error) {
+	dir := getDesktopEntriesDir()
+	exist, err := desktop.Exist(dir, profileName)
+	if err != nil || exist {
+		return exist, err
+	}
+	exist, err = desktop.CombinedExist(dir)
+	if err != nil || exist {
+		return exist, err
+	}
+	return desktop.AutostartExist(getAutostartDir(), profileName)
+}
+
+// newHandlerExec returns the command line of the URL handler desktop entry
+func newHandlerExec(execPath string) string {
+	return desktop.ExecLine(execPath, "open", "--") + " %u"
+}
+
+func writeHandlerDesktopEntry(conf *config.Config) error {
+	execPath, err := getDesktopExecPath(conf)
+	if err != nil {
+		return err
+	}
+	if err := desktop.CreateHandler(getDesktopEntriesDir(), execPath, newHandlerExec(execPath)); err != nil {
+		return newError("Failed to create URL handler desktop entry.", err)
+	}
+	return nil
+}
+
+// updateCombinedDesktopEntry regenerates the combined desktop entry if it exists,
+// the entry is removed when the last profile is gone
+func updateCombinedDesktopEntry(conf *config.Config) error {
+	dir := getDesktopEntriesDir()
+	exist, err := desktop.CombinedExist(dir)
+	if err != nil || !exist {
+		return err
+	}
+	profiles, err := listProfiles(conf.ProfileDir)
 	if err != nil {
 		return err
 	}
-	if err := desktop.Create(dir, profileName, "manygram", "manygram run "+profileName); err != nil {
-		return newError("Failed to create desktop entry for profile '%s'", profileName, err)
+	if len(profiles) == 0 {
+		if err := desktop.RemoveCombined(dir); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return newError("Failed to remove combined desktop entry.", err)
+		}
+		printMessage("Combined desktop entry has been removed.")
+		return nil
+	}
+	return writeCombinedDesktopEntry(conf)
+}
+
+// getWMClass returns the window class of the profile, it is unique by default
+// to prevent docks from grouping windows of all profiles together
+func getWMClass(prof *profile.Profile, profConf *config.ProfileConfig) string {
+	if profConf.WMClass != "" {
+		return profConf.WMClass
+	}
+	return "manygram-" + prof.Name
+}
+
+// writeProfileIcon generates the profile icon if the profile has no custom icon
+// and removes the previously generated one otherwise
+func writeProfileIcon(prof *profile.Profile, profConf *config.ProfileConfig) error {
+	if profConf.Icon != "" {
+		return removeProfileIcon(prof.Name)
+	}
+	err := icon.Generate(getIconsDir(), &icon.Icon{
+		Profile: prof.Name,
+		Letter:  icon.Letter(profConf.DisplayName, prof.Name),
+		Color:   profConf.Color,
+		Style:   icon.Style(profConf.IconStyle),
+	})
+	if err != nil {
+		return newError("Failed to generate icon for profile '%s'.", prof.Name, err)
+	}
+	return nil
+}
+
+func removeProfileIcon(profileName string) error {
+	if err := icon.Remove(getIconsDir(), profileName); err != nil {
+		return newError("Failed to remove icon of profile '%s'.", profileName, err)
 	}
 	return nil
 }
@@ -89,7 +616,11 @@ func createDesktopEntry(conf *config.Config, profileName string) error {
 func removeDesktopEntry(profileName string) error {
 	err := desktop.Remove(getDesktopEntriesDir(), profileName)
 	if err == nil {
-		return nil
+		inUse, err := isProfileIconInUse(profileName)
+		if err != nil || inUse {
+			return err
+		}
+		return removeProfileIcon(profileName)
 	} else if errors.Is(err, os.ErrNotExist) {
 		return newError("Desktop entry for profile '%s' does not exist.", profileName)
 	}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputTSV  = "tsv"
+)
+
+// field is a named value of the structured output record
+type field struct {
+	key   string
+	value interface{}
+}
+
+// record is a structured output record with stable ordering of fields
+type record []field
+
+func (r record) MarshalJSON() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	for idx, f := range r {
+		if idx > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		if v, ok := f.value.([]string); ok && v == nil {
+			f.value = []string{}
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (r record) keys() []string {
+	keys := make([]string, len(r))
+	for idx, f := range r {
+		keys[idx] = f.key
+	}
+	return keys
+}
+
+func (r record) values() []string {
+	values := make([]string, len(r))
+	for idx, f := range r {
+		values[idx] = formatTSVValue(f.value)
+	}
+	return values
+}
+
+var tsvReplacer = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+func formatTSVValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []string:
+		return tsvReplacer.Replace(strings.Join(v, ","))
+	default:
+		return tsvReplacer.Replace(fmt.Sprint(v))
+	}
+}
+
+func isTextOutput() bool {
+	return options.Output == outputText
+}
+
+// printRecord prints a single record in the structured output format
+func printRecord(rec record) error {
+	if options.Output == outputJSON {
+		return json.NewEncoder(os.Stdout).Encode(rec)
+	}
+	return printTSV(rec.keys(), []record{rec})
+}
+
+// printRecords prints a list of records in the structured output format,
+// keys are used as TSV header and are required for empty lists
+func printRecords(keys []string, records []record) error {
+	if options.Output == outputJSON {
+		if records == nil {
+			records = []record{}
+		}
+		return json.NewEncoder(os.Stdout).Encode(records)
+	}
+	return printTSV(keys, records)
+}
+
+func printTSV(keys []string, records []record) error {
+	lines := make([]string, 0, len(records)+1)
+	lines = append(lines, strings.Join(keys, "\t"))
+	for _, rec := range records {
+		lines = append(lines, strings.Join(rec.values(), "\t"))
+	}
+	_, err := fmt.Fprintln(os.Stdout, strings.Join(lines, "\n"))
+	return err
+}
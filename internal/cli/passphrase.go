@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/un-def/manygram/internal/archive"
+	"github.com/un-def/manygram/internal/config"
+	"github.com/un-def/manygram/internal/crypt"
+)
+
+const passphraseEnv = "MANYGRAM_PASSPHRASE"
+
+// passphraseOption is used by commands reading or writing encrypted archives
+type passphraseOption struct {
+	PassphraseFile string `long:"passphrase-file" description:"Read the passphrase from the file" value-name:"FILE"`
+}
+
+// readPassphrase reads the passphrase from --passphrase-file, MANYGRAM_PASSPHRASE environment variable,
+// 'passphrase-file' config parameter or the terminal, the passphrase is asked twice if confirm is true
+func (o *passphraseOption) readPassphrase(conf *config.Config, confirm bool) ([]byte, error) {
+	passphraseFile := o.PassphraseFile
+	if passphraseFile == "" {
+		if passphrase, ok := os.LookupEnv(passphraseEnv); ok {
+			if passphrase == "" {
+				return nil, newError("%s environment variable is empty.", passphraseEnv)
+			}
+			return []byte(passphrase), nil
+		}
+		passphraseFile = conf.PassphraseFile
+	}
+	if passphraseFile != "" {
+		content, err := ioutil.ReadFile(passphraseFile)
+		if err != nil {
+			return nil, newError("Failed to read passphrase file %s.", passphraseFile, err)
+		}
+		// only the first line is used to let the file end with a newline
+		passphrase := bytes.SplitN(content, []byte("\n"), 2)[0]
+		passphrase = bytes.TrimSuffix(passphrase, []byte("\r"))
+		if len(passphrase) == 0 {
+			return nil, newError("Passphrase file %s is empty.", passphraseFile)
+		}
+		return passphrase, nil
+	}
+	if !isTerminal(os.Stdin) {
+		return nil, newError(
+			"Passphrase is required. Use --passphrase-file option or %s environment variable.", passphraseEnv,
+		)
+	}
+	passphrase, err := promptPassphrase("Passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, newError("Passphrase must not be empty.")
+	}
+	if confirm {
+		repeated, err := promptPassphrase("Repeat passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(passphrase, repeated) {
+			return nil, newError("Passphrases do not match.")
+		}
+	}
+	return passphrase, nil
+}
+
+func promptPassphrase(prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, newError("Failed to read passphrase.", err)
+	}
+	return passphrase, nil
+}
+
+// newArchiveReader reads the archive file decrypting it with the passphrase if the archive is encrypted
+func (o *passphraseOption) newArchiveReader(conf *config.Config, file *os.File) (*archive.Reader, error) {
+	br := bufio.NewReader(file)
+	encrypted, err := crypt.IsEncrypted(br)
+	if err != nil {
+		return nil, newError("Failed to read archive %s.", file.Name(), err)
+	}
+	var r io.Reader = br
+	if encrypted {
+		passphrase, err := o.readPassphrase(conf, false)
+		if err != nil {
+			return nil, err
+		}
+		r, err = crypt.NewReader(br, passphrase)
+		if errors.Is(err, crypt.ErrWrongPassphrase) {
+			return nil, newError("Wrong passphrase for archive %s.", file.Name())
+		}
+		if err != nil {
+			return nil, newError("Failed to decrypt archive %s.", file.Name(), err)
+		}
+	}
+	ar, err := archive.NewReader(r)
+	if err != nil {
+		return nil, newError("Failed to read archive %s.", file.Name(), err)
+	}
+	return ar, nil
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/un-def/manygram/internal/profile"
+)
+
+const pickerTitle = "manygram"
+const pickerText = "Select a profile"
+
+// pickProfile asks the user to pick one of the profiles in the terminal if stdin is a TTY
+// or in a zenity/kdialog dialog otherwise
+func pickProfile(profiles []*profile.Profile) (*profile.Profile, error) {
+	if len(profiles) == 0 {
+		return nil, newError("There are no profiles. Use `manygram create PROFILE` to create a new one.")
+	}
+	labels := make([]string, len(profiles))
+	for idx, prof := range profiles {
+		profConf, err := readProfileConfig(prof)
+		if err != nil {
+			return nil, err
+		}
+		labels[idx] = prof.Name
+		if profConf.DisplayName != "" {
+			labels[idx] = profConf.DisplayName
+		}
+	}
+	var name string
+	var err error
+	if isTerminal(os.Stdin) {
+		name, err = pickInTerminal(profiles, labels)
+	} else {
+		name, err = pickInDialog(profiles, labels)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, prof := range profiles {
+		if prof.Name == name {
+			return prof, nil
+		}
+	}
+	return nil, newError("Profile '%s' does not exist.", name)
+}
+
+func isTerminal(file *os.File) bool {
+	return term.IsTerminal(int(file.Fd()))
+}
+
+func pickInTerminal(profiles []*profile.Profile, labels []string) (string, error) {
+	for idx, prof := range profiles {
+		if labels[idx] == prof.Name {
+			fmt.Fprintf(os.Stderr, "%d) %s\n", idx+1, prof.Name)
+		} else {
+			fmt.Fprintf(os.Stderr, "%d) %s (%s)\n", idx+1, prof.Name, labels[idx])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%s [1-%d]: ", pickerText, len(profiles))
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		if err != nil {
+			fmt.Fprintln(os.Stderr)
+		}
+		return "", newError("No profile selected.")
+	}
+	if number, err := strconv.Atoi(answer); err == nil {
+		if number < 1 || number > len(profiles) {
+			return "", newError("Invalid profile number %d.", number)
+		}
+		return profiles[number-1].Name, nil
+	}
+	return answer, nil
+}
+
+func pickInDialog(profiles []*profile.Profile, labels []string) (string, error) {
+	var args []string
+	command, err := exec.LookPath("zenity")
+	if err == nil {
+		args = []string{"--list", "--title=" + pickerTitle, "--text=" + pickerText, "--column=Profile", "--column=Name"}
+	} else if command, err = exec.LookPath("kdialog"); err == nil {
+		args = []string{"--title", pickerTitle, "--menu", pickerText}
+	} else {
+		return "", newError(
+			"Cannot ask which profile to use: neither terminal nor zenity/kdialog is available. Set `default-profile` in the config.",
+		)
+	}
+	for idx, prof := range profiles {
+		args = append(args, prof.Name, labels[idx])
+	}
+	output, err := exec.Command(command, args...).Output()
+	name := strings.TrimSpace(string(output))
+	if err != nil || name == "" {
+		return "", newError("No profile selected.")
+	}
+	return name, nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -11,12 +12,45 @@ import (
 	"github.com/BurntSushi/toml"
 )
 
+// Values of the `desktop-exec` option
+const (
+	// DesktopExecAbsolute makes desktop entries run manygram by its absolute path, it is the default
+	DesktopExecAbsolute = "absolute"
+	// DesktopExecPath makes desktop entries look up manygram in PATH
+	DesktopExecPath = "path"
+)
+
+// Values of the `open-mode` option selecting the profile opening tg:// links
+const (
+	// OpenModeDefault opens links with the `default-profile`
+	OpenModeDefault = "default"
+	// OpenModeLastUsed opens links with the last run profile, it is the default
+	OpenModeLastUsed = "last-used"
+	// OpenModePick asks the user to pick a profile
+	OpenModePick = "pick"
+)
+
 // Config ...
 type Config struct {
-	path       string
-	ExecPath   string   `toml:"exec-path"`
-	ExecArgs   []string `toml:"exec-args"`
-	ProfileDir string   `toml:"profile-dir"`
+	path             string
+	ExecPath         string            `toml:"exec-path"`
+	ExecArgs         []string          `toml:"exec-args"`
+	ProfileDir       string            `toml:"profile-dir"`
+	EphemeralDir     string            `toml:"ephemeral-dir,omitempty"`
+	DesktopExec      string            `toml:"desktop-exec,omitempty"`
+	OpenMode         string            `toml:"open-mode,omitempty"`
+	DefaultProfile   string            `toml:"default-profile,omitempty"`
+	Log              bool              `toml:"log,omitempty"`
+	LogMaxSize       int64             `toml:"log-max-size,omitempty"`
+	LogMaxFiles      int               `toml:"log-max-files,omitempty"`
+	BackupDir        string            `toml:"backup-dir,omitempty"`
+	BackupKeepLast   *int              `toml:"backup-keep-last,omitempty"`
+	BackupKeepDaily  *int              `toml:"backup-keep-daily,omitempty"`
+	BackupKeepWeekly *int              `toml:"backup-keep-weekly,omitempty"`
+	BackupEncrypt    bool              `toml:"backup-encrypt,omitempty"`
+	PassphraseFile   string            `toml:"passphrase-file,omitempty"`
+	Env              map[string]string `toml:"env,omitempty"`
+	UnsetEnv         []string          `toml:"unset-env,omitempty"`
 }
 
 func (c *Config) Write() error {
@@ -65,7 +99,46 @@ func Read(path string) (*Config, error) {
 		return nil, errors.New("`profile-dir` parameter is empty")
 	}
 	conf.ProfileDir = profileDir
+	conf.EphemeralDir = strings.TrimSpace(conf.EphemeralDir)
+
+	switch conf.DesktopExec {
+	case "", DesktopExecAbsolute, DesktopExecPath:
+	default:
+		return nil, fmt.Errorf("`desktop-exec` parameter must be either %q or %q", DesktopExecAbsolute, DesktopExecPath)
+	}
+
+	switch conf.OpenMode {
+	case "", OpenModeDefault, OpenModeLastUsed, OpenModePick:
+	default:
+		return nil, fmt.Errorf(
+			"`open-mode` parameter must be one of %q, %q or %q",
+			OpenModeDefault, OpenModeLastUsed, OpenModePick,
+		)
+	}
+	conf.DefaultProfile = strings.TrimSpace(conf.DefaultProfile)
+
+	if conf.LogMaxSize < 0 {
+		return nil, errors.New("`log-max-size` parameter must not be negative")
+	}
+	if conf.LogMaxFiles < 0 {
+		return nil, errors.New("`log-max-files` parameter must not be negative")
+	}
+
+	conf.BackupDir = strings.TrimSpace(conf.BackupDir)
+	for _, keep := range []*int{conf.BackupKeepLast, conf.BackupKeepDaily, conf.BackupKeepWeekly} {
+		if keep != nil && *keep < 0 {
+			return nil, errors.New("`backup-keep-*` parameters must not be negative")
+		}
+	}
+	if isSetToZero(conf.BackupKeepLast) && isSetToZero(conf.BackupKeepDaily) && isSetToZero(conf.BackupKeepWeekly) {
+		return nil, errors.New("`backup-keep-*` parameters must not all be 0")
+	}
+	conf.PassphraseFile = strings.TrimSpace(conf.PassphraseFile)
 
 	conf.path = path
 	return conf, nil
 }
+
+func isSetToZero(value *int) bool {
+	return value != nil && *value == 0
+}
@@ -113,6 +113,120 @@ func (s *TestConfigReadSuite) TestReadOK() {
 	}, conf)
 }
 
+func (s *TestConfigReadSuite) TestReadDesktopExec() {
+	s.WriteConfig(`
+		exec-path = "/path/to/bin"
+		profile-dir = "/path/to/profiles"
+		desktop-exec = "path"
+	`)
+	conf, err := Read(s.path)
+	s.Require().NoError(err)
+	s.Require().Equal(DesktopExecPath, conf.DesktopExec)
+}
+
+func (s *TestConfigReadSuite) TestReadInvalidDesktopExec() {
+	s.WriteConfig(`
+		exec-path = "/path/to/bin"
+		profile-dir = "/path/to/profiles"
+		desktop-exec = "relative"
+	`)
+	conf, err := Read(s.path)
+	s.Require().Error(err)
+	s.Require().Regexp("desktop-exec.*must be", err.Error())
+	s.Require().Nil(conf)
+}
+
+func (s *TestConfigReadSuite) TestReadOpenMode() {
+	s.WriteConfig(`
+		exec-path = "/path/to/bin"
+		profile-dir = "/path/to/profiles"
+		open-mode = "default"
+		default-profile = " work "
+	`)
+	conf, err := Read(s.path)
+	s.Require().NoError(err)
+	s.Require().Equal(OpenModeDefault, conf.OpenMode)
+	s.Require().Equal("work", conf.DefaultProfile)
+}
+
+func (s *TestConfigReadSuite) TestReadInvalidOpenMode() {
+	s.WriteConfig(`
+		exec-path = "/path/to/bin"
+		profile-dir = "/path/to/profiles"
+		open-mode = "random"
+	`)
+	conf, err := Read(s.path)
+	s.Require().Error(err)
+	s.Require().Regexp("open-mode.*must be", err.Error())
+	s.Require().Nil(conf)
+}
+
+func (s *TestConfigReadSuite) TestReadEphemeralDir() {
+	s.WriteConfig(`
+		exec-path = "/path/to/bin"
+		profile-dir = "/path/to/profiles"
+		ephemeral-dir = " /path/to/tmp "
+	`)
+	conf, err := Read(s.path)
+	s.Require().NoError(err)
+	s.Require().Equal("/path/to/tmp", conf.EphemeralDir)
+}
+
+func (s *TestConfigReadSuite) TestReadBackup() {
+	s.WriteConfig(`
+		exec-path = "/path/to/bin"
+		profile-dir = "/path/to/profiles"
+		backup-dir = " /path/to/backups "
+		backup-keep-last = 2
+		backup-keep-weekly = 0
+	`)
+	conf, err := Read(s.path)
+	s.Require().NoError(err)
+	s.Require().Equal("/path/to/backups", conf.BackupDir)
+	s.Require().Equal(2, *conf.BackupKeepLast)
+	s.Require().Nil(conf.BackupKeepDaily)
+	s.Require().Equal(0, *conf.BackupKeepWeekly)
+}
+
+func (s *TestConfigReadSuite) TestReadBackupEncrypt() {
+	s.WriteConfig(`
+		exec-path = "/path/to/bin"
+		profile-dir = "/path/to/profiles"
+		backup-encrypt = true
+		passphrase-file = " /path/to/passphrase "
+	`)
+	conf, err := Read(s.path)
+	s.Require().NoError(err)
+	s.Require().True(conf.BackupEncrypt)
+	s.Require().Equal("/path/to/passphrase", conf.PassphraseFile)
+}
+
+func (s *TestConfigReadSuite) TestReadInvalidBackupKeep() {
+	s.WriteConfig(`
+		exec-path = "/path/to/bin"
+		profile-dir = "/path/to/profiles"
+		backup-keep-daily = -1
+	`)
+	conf, err := Read(s.path)
+	s.Require().Error(err)
+	s.Require().Regexp("backup-keep-.*must not be negative", err.Error())
+	s.Require().Nil(conf)
+}
+
+func (s *TestConfigReadSuite) TestReadAllBackupKeepZero() {
+	s.WriteConfig(`
+		exec-path = "/path/to/bin"
+		profile-dir = "/path/to/profiles"
+		backup-keep-last = 0
+		backup-keep-daily = 0
+		backup-keep-weekly = 0
+	`)
+	conf, err := Read(s.path)
+	s.Require().Error(err)
+	s.Require().Regexp("backup-keep-.*must not all be 0", err.Error())
+	s.Require().Nil(conf)
+}
+
 func TestConfigReadSuiteTest(t *testing.T) {
 	suite.Run(t, new(TestConfigReadSuite))
 }
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ProfileConfigName is the name of the per-profile config stored in the profile directory
+const ProfileConfigName = "manygram.toml"
+
+// ProfileConfig holds per-profile settings overriding the global config
+type ProfileConfig struct {
+	path         string
+	DisplayName  string            `toml:"display-name,omitempty"`
+	Description  string            `toml:"description,omitempty"`
+	Icon         string            `toml:"icon,omitempty"`
+	IconStyle    string            `toml:"icon-style,omitempty"`
+	Color        string            `toml:"color,omitempty"`
+	Tags         []string          `toml:"tags,omitempty"`
+	WMClass      string            `toml:"wm-class,omitempty"`
+	DesktopEntry bool              `toml:"desktop-entry,omitempty"`
+	Autostart    bool              `toml:"autostart,omitempty"`
+	Log          *bool             `toml:"log,omitempty"`
+	ExecPath     string            `toml:"exec-path,omitempty"`
+	ExecArgs     []string          `toml:"exec-args,omitempty"`
+	Env          map[string]string `toml:"env,omitempty"`
+	UnsetEnv     []string          `toml:"unset-env,omitempty"`
+}
+
+var colorRegexp = regexp.MustCompile("^#([0-9A-Fa-f]{3}|[0-9A-Fa-f]{6})$")
+
+// IsValidColor checks whether the color is in #RGB or #RRGGBB format
+func IsValidColor(color string) bool {
+	return colorRegexp.MatchString(color)
+}
+
+var wmClassRegexp = regexp.MustCompile("^[A-Za-z0-9._-]+$")
+
+// IsValidWMClass checks whether the window class consists of letters, digits, dots, underscores and hyphens
+func IsValidWMClass(wmClass string) bool {
+	return wmClassRegexp.MatchString(wmClass)
+}
+
+// ProfileConfigPath builds the path to the per-profile config
+func ProfileConfigPath(profilePath string) string {
+	return filepath.Join(profilePath, ProfileConfigName)
+}
+
+// ReadProfile reads the per-profile config from the profile directory,
+// an empty config is returned if the file does not exist
+func ReadProfile(profilePath string) (*ProfileConfig, error) {
+	path := ProfileConfigPath(profilePath)
+	conf := &ProfileConfig{path: path}
+	bs, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return conf, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	md, err := toml.Decode(string(bs), conf)
+	if err != nil {
+		return nil, err
+	}
+	if md.IsDefined("exec-path") {
+		execPath := strings.TrimSpace(conf.ExecPath)
+		if execPath == "" {
+			return nil, errors.New("`exec-path` parameter is empty")
+		}
+		conf.ExecPath = execPath
+	}
+	if conf.Color != "" && !IsValidColor(conf.Color) {
+		return nil, errors.New("`color` parameter must be in #RRGGBB or #RGB format")
+	}
+	if conf.WMClass != "" && !IsValidWMClass(conf.WMClass) {
+		return nil, errors.New("`wm-class` parameter must consist of letters, digits, dots, underscores and hyphens")
+	}
+	return conf, nil
+}
+
+// Write writes the per-profile config into the profile directory
+func (c *ProfileConfig) Write() error {
+	buf := new(bytes.Buffer)
+	if err := toml.NewEncoder(buf).Encode(c); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, buf.Bytes(), 0644)
+}
+
+// Merge returns a copy of the global config with the per-profile overrides applied;
+// `exec-args` of the global config are not used if the profile overrides `exec-path`;
+// the profile `env` and `unset-env` take precedence over the global ones
+func (c *Config) Merge(pc *ProfileConfig) *Config {
+	merged := *c
+	if pc.Log != nil {
+		merged.Log = *pc.Log
+	}
+	if pc.ExecPath != "" {
+		merged.ExecPath = pc.ExecPath
+		merged.ExecArgs = pc.ExecArgs
+	} else if pc.ExecArgs != nil {
+		merged.ExecArgs = pc.ExecArgs
+	}
+	if len(pc.Env) == 0 && len(pc.UnsetEnv) == 0 {
+		return &merged
+	}
+	env := make(map[string]string, len(c.Env)+len(pc.Env))
+	for key, value := range c.Env {
+		env[key] = value
+	}
+	for _, key := range pc.UnsetEnv {
+		delete(env, key)
+	}
+	for key, value := range pc.Env {
+		env[key] = value
+	}
+	var unsetEnv []string
+	seen := make(map[string]bool)
+	for _, key := range append(append([]string{}, c.UnsetEnv...), pc.UnsetEnv...) {
+		if _, ok := env[key]; ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unsetEnv = append(unsetEnv, key)
+	}
+	merged.Env = env
+	merged.UnsetEnv = unsetEnv
+	return &merged
+}
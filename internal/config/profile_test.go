@@ -0,0 +1,172 @@
+package config
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestProfileConfigSuite struct {
+	BaseSuite
+}
+
+func (s *TestProfileConfigSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	s.path = path.Join(s.dir, ProfileConfigName)
+}
+
+func (s *TestProfileConfigSuite) TestReadNotExist() {
+	conf, err := ReadProfile(s.dir)
+	s.Require().NoError(err)
+	s.Require().Equal(&ProfileConfig{path: s.path}, conf)
+}
+
+func (s *TestProfileConfigSuite) TestReadOK() {
+	s.WriteConfig(`
+		exec-path = " flatpak "
+		exec-args = ["run", "--branch=beta", "org.telegram.desktop"]
+
+		[env]
+		QT_SCALE_FACTOR = "1.5"
+	`)
+	conf, err := ReadProfile(s.dir)
+	s.Require().NoError(err)
+	s.Require().Equal(&ProfileConfig{
+		path:     s.path,
+		ExecPath: "flatpak",
+		ExecArgs: []string{"run", "--branch=beta", "org.telegram.desktop"},
+		Env:      map[string]string{"QT_SCALE_FACTOR": "1.5"},
+	}, conf)
+}
+
+func (s *TestProfileConfigSuite) TestReadMetadata() {
+	s.WriteConfig(`
+		display-name = "Работа 💼"
+		description = "Support desk account"
+		icon = "/path/to/icon.png"
+		color = "#2AABEE"
+		tags = ["work", "support"]
+	`)
+	conf, err := ReadProfile(s.dir)
+	s.Require().NoError(err)
+	s.Require().Equal(&ProfileConfig{
+		path:        s.path,
+		DisplayName: "Работа 💼",
+		Description: "Support desk account",
+		Icon:        "/path/to/icon.png",
+		Color:       "#2AABEE",
+		Tags:        []string{"work", "support"},
+	}, conf)
+}
+
+func (s *TestProfileConfigSuite) TestReadInvalidColor() {
+	s.WriteConfig(`color = "blue"`)
+	conf, err := ReadProfile(s.dir)
+	s.Require().Error(err)
+	s.Require().Regexp("color.*format", err.Error())
+	s.Require().Nil(conf)
+}
+
+func (s *TestProfileConfigSuite) TestIsValidColor() {
+	for _, color := range []string{"#fff", "#2AABEE", "#2aabee"} {
+		s.Require().True(IsValidColor(color), color)
+	}
+	for _, color := range []string{"", "fff", "#ffff", "#GGGGGG", "blue"} {
+		s.Require().False(IsValidColor(color), color)
+	}
+}
+
+func (s *TestProfileConfigSuite) TestReadInvalidWMClass() {
+	s.WriteConfig(`wm-class = "foo\nExec=evil"`)
+	conf, err := ReadProfile(s.dir)
+	s.Require().Error(err)
+	s.Require().Regexp("wm-class.*must consist of", err.Error())
+	s.Require().Nil(conf)
+}
+
+func (s *TestProfileConfigSuite) TestIsValidWMClass() {
+	for _, wmClass := range []string{"manygram-foo", "Telegram.Desktop", "tg_2"} {
+		s.Require().True(IsValidWMClass(wmClass), wmClass)
+	}
+	for _, wmClass := range []string{"", "foo bar", "foo\nExec=evil", "foo;bar"} {
+		s.Require().False(IsValidWMClass(wmClass), wmClass)
+	}
+}
+
+func (s *TestProfileConfigSuite) TestReadEmptyExecPath() {
+	s.WriteConfig(`exec-path = ""`)
+	conf, err := ReadProfile(s.dir)
+	s.Require().Error(err)
+	s.Require().Regexp("exec-path.*is empty", err.Error())
+	s.Require().Nil(conf)
+}
+
+func (s *TestProfileConfigSuite) TestWrite() {
+	conf, err := ReadProfile(s.dir)
+	s.Require().NoError(err)
+	conf.ExecArgs = []string{"-debug"}
+	conf.Env = map[string]string{"TZ": "UTC"}
+	s.Require().NoError(conf.Write())
+	content, err := ioutil.ReadFile(s.path)
+	s.Require().NoError(err)
+	s.Require().NotContains(string(content), "exec-path")
+	reread, err := ReadProfile(s.dir)
+	s.Require().NoError(err)
+	s.Require().Equal(conf, reread)
+}
+
+func (s *TestProfileConfigSuite) TestMerge() {
+	global := &Config{
+		path:       "/path/to/config.toml",
+		ExecPath:   "flatpak",
+		ExecArgs:   []string{"run", "org.telegram.desktop"},
+		ProfileDir: "/path/to/profiles",
+	}
+	s.Require().Equal(global, global.Merge(&ProfileConfig{}))
+	s.Require().Equal(&Config{
+		path:       "/path/to/config.toml",
+		ExecPath:   "/usr/bin/telegram-desktop",
+		ProfileDir: "/path/to/profiles",
+	}, global.Merge(&ProfileConfig{ExecPath: "/usr/bin/telegram-desktop"}))
+	s.Require().Equal(&Config{
+		path:       "/path/to/config.toml",
+		ExecPath:   "flatpak",
+		ExecArgs:   []string{"run", "--branch=beta", "org.telegram.desktop"},
+		ProfileDir: "/path/to/profiles",
+	}, global.Merge(&ProfileConfig{ExecArgs: []string{"run", "--branch=beta", "org.telegram.desktop"}}))
+	s.Require().Equal([]string{"run", "org.telegram.desktop"}, global.ExecArgs)
+}
+
+func (s *TestProfileConfigSuite) TestMergeLog() {
+	enabled, disabled := true, false
+	global := &Config{ExecPath: "telegram-desktop", Log: true}
+	s.Require().True(global.Merge(&ProfileConfig{}).Log)
+	s.Require().False(global.Merge(&ProfileConfig{Log: &disabled}).Log)
+	global.Log = false
+	s.Require().True(global.Merge(&ProfileConfig{Log: &enabled}).Log)
+}
+
+func (s *TestProfileConfigSuite) TestMergeEnv() {
+	global := &Config{
+		ExecPath: "telegram-desktop",
+		Env:      map[string]string{"QT_QPA_PLATFORM": "xcb", "TZ": "UTC", "LANG": "en_US.UTF-8"},
+		UnsetEnv: []string{"http_proxy", "QT_SCALE_FACTOR"},
+	}
+	merged := global.Merge(&ProfileConfig{
+		Env:      map[string]string{"TZ": "Europe/Berlin", "QT_SCALE_FACTOR": "2"},
+		UnsetEnv: []string{"LANG", "https_proxy", "http_proxy"},
+	})
+	s.Require().Equal(map[string]string{
+		"QT_QPA_PLATFORM": "xcb",
+		"TZ":              "Europe/Berlin",
+		"QT_SCALE_FACTOR": "2",
+	}, merged.Env)
+	s.Require().Equal([]string{"http_proxy", "LANG", "https_proxy"}, merged.UnsetEnv)
+	s.Require().Equal(map[string]string{"QT_QPA_PLATFORM": "xcb", "TZ": "UTC", "LANG": "en_US.UTF-8"}, global.Env)
+}
+
+func TestProfileConfigSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestProfileConfigSuite))
+}
@@ -0,0 +1,259 @@
+package crypt
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Extension is appended to the names of encrypted files
+const Extension = ".enc"
+
+// Magic starts every encrypted file
+var Magic = []byte("manygram-enc\x00")
+
+const version = 1
+const saltSize = 16
+const keySize = 32
+const macSize = sha256.Size
+const chunkSize = 64 * 1024
+
+// Params are parameters of the Argon2id key derivation function
+type Params struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+}
+
+// DefaultParams are used to encrypt new files
+var DefaultParams = Params{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// maxMemory limits the memory requested by the header of the file being decrypted, KiB
+const maxMemory = 1024 * 1024
+
+// headerSize is the size of magic, version, params and salt followed by the passphrase check
+var headerSize = len(Magic) + 1 + 4 + 4 + 1 + saltSize
+
+// ErrWrongPassphrase is returned by the NewReader() function when the passphrase does not match
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// ErrNotEncrypted is returned by the NewReader() function when the data does not start with the magic
+var ErrNotEncrypted = errors.New("not encrypted")
+
+// ErrUnsupportedVersion is returned by the NewReader() function for files written by newer versions
+var ErrUnsupportedVersion = errors.New("unsupported encryption version")
+
+// ErrCorrupted is returned by the reader when the data has been truncated or modified
+var ErrCorrupted = errors.New("encrypted data is corrupted")
+
+// IsEncrypted reports whether the data read by r starts with the magic without consuming it
+func IsEncrypted(r *bufio.Reader) (bool, error) {
+	magic, err := r.Peek(len(Magic))
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	return bytes.Equal(magic, Magic), nil
+}
+
+// deriveKeys derives the encryption key and the key used to check the passphrase
+func deriveKeys(passphrase []byte, salt []byte, params Params) ([]byte, []byte) {
+	key := argon2.IDKey(passphrase, salt, params.Time, params.Memory, params.Threads, 2*keySize)
+	return key[:keySize], key[keySize:]
+}
+
+func checksum(checkKey []byte, header []byte) []byte {
+	mac := hmac.New(sha256.New, checkKey)
+	mac.Write(header)
+	return mac.Sum(nil)
+}
+
+// nonce returns the nonce of the chunk, the last chunk is marked
+// to detect the truncation at the chunk boundary
+func nonce(counter uint64, last bool) []byte {
+	n := make([]byte, 12)
+	binary.BigEndian.PutUint64(n[3:11], counter)
+	if last {
+		n[11] = 1
+	}
+	return n
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Writer encrypts the data with AES-256-GCM in chunks
+type Writer struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	header  []byte
+	buf     []byte
+	counter uint64
+}
+
+// NewWriter writes the header and returns the writer encrypting the data with the passphrase,
+// the Close() method must be called to write the last chunk
+func NewWriter(w io.Writer, passphrase []byte, params Params) (*Writer, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	header := make([]byte, 0, headerSize)
+	header = append(header, Magic...)
+	header = append(header, version)
+	header = appendUint32(header, params.Time)
+	header = appendUint32(header, params.Memory)
+	header = append(header, params.Threads)
+	header = append(header, salt...)
+	key, checkKey := deriveKeys(passphrase, salt, params)
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append(header, checksum(checkKey, header)...)); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, aead: aead, header: header, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func appendUint32(bs []byte, v uint32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	return append(bs, b[:]...)
+}
+
+func (ew *Writer) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if len(ew.buf) == chunkSize {
+			if err := ew.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(ew.buf[len(ew.buf):chunkSize], p)
+		ew.buf = ew.buf[:len(ew.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (ew *Writer) flush(last bool) error {
+	sealed := ew.aead.Seal(nil, nonce(ew.counter, last), ew.buf, ew.header)
+	ew.counter++
+	ew.buf = ew.buf[:0]
+	_, err := ew.w.Write(sealed)
+	return err
+}
+
+// Close writes the last chunk, the underlying writer is not closed
+func (ew *Writer) Close() error {
+	return ew.flush(true)
+}
+
+type reader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	header  []byte
+	chunk   []byte
+	buf     []byte
+	counter uint64
+	eof     bool
+}
+
+// NewReader reads the header, checks the passphrase and returns the reader decrypting the data
+func NewReader(r io.Reader, passphrase []byte) (io.Reader, error) {
+	header := make([]byte, headerSize+macSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrNotEncrypted
+		}
+		return nil, err
+	}
+	if !bytes.HasPrefix(header, Magic) {
+		return nil, ErrNotEncrypted
+	}
+	offset := len(Magic)
+	if header[offset] != version {
+		return nil, ErrUnsupportedVersion
+	}
+	offset++
+	params := Params{
+		Time:    binary.BigEndian.Uint32(header[offset:]),
+		Memory:  binary.BigEndian.Uint32(header[offset+4:]),
+		Threads: header[offset+8],
+	}
+	offset += 9
+	if params.Time == 0 || params.Threads == 0 || params.Memory > maxMemory {
+		return nil, ErrCorrupted
+	}
+	salt := header[offset : offset+saltSize]
+	key, checkKey := deriveKeys(passphrase, salt, params)
+	if !hmac.Equal(checksum(checkKey, header[:headerSize]), header[headerSize:]) {
+		return nil, ErrWrongPassphrase
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &reader{
+		r:      bufio.NewReader(r),
+		aead:   aead,
+		header: header[:headerSize],
+		chunk:  make([]byte, chunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (er *reader) Read(p []byte) (int, error) {
+	for len(er.buf) == 0 {
+		if er.eof {
+			return 0, io.EOF
+		}
+		if err := er.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, er.buf)
+	er.buf = er.buf[n:]
+	return n, nil
+}
+
+// readChunk decrypts the next chunk, the chunk is the last one if nothing follows it
+func (er *reader) readChunk() error {
+	n, err := io.ReadFull(er.r, er.chunk)
+	if err == io.EOF {
+		return ErrCorrupted
+	}
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	last := err == io.ErrUnexpectedEOF
+	if !last {
+		if _, err := er.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+	plain, err := er.aead.Open(er.chunk[:0], nonce(er.counter, last), er.chunk[:n], er.header)
+	if err != nil {
+		return ErrCorrupted
+	}
+	er.counter++
+	er.buf = plain
+	er.eof = last
+	return nil
+}
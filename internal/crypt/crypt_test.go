@@ -0,0 +1,96 @@
+package crypt
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var testParams = Params{Time: 1, Memory: 64, Threads: 1}
+
+type TestCryptSuite struct {
+	suite.Suite
+}
+
+func (s *TestCryptSuite) Encrypt(data []byte, passphrase string) []byte {
+	buf := new(bytes.Buffer)
+	w, err := NewWriter(buf, []byte(passphrase), testParams)
+	s.Require().NoError(err)
+	_, err = w.Write(data)
+	s.Require().NoError(err)
+	s.Require().NoError(w.Close())
+	return buf.Bytes()
+}
+
+func (s *TestCryptSuite) Decrypt(data []byte, passphrase string) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(data), []byte(passphrase))
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+func (s *TestCryptSuite) TestRoundTrip() {
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 100} {
+		data := make([]byte, size)
+		rand.Read(data)
+		encrypted := s.Encrypt(data, "secret")
+		decrypted, err := s.Decrypt(encrypted, "secret")
+		s.Require().NoError(err, size)
+		s.Require().Equal(data, decrypted, size)
+	}
+}
+
+func (s *TestCryptSuite) TestWrongPassphrase() {
+	encrypted := s.Encrypt([]byte("data"), "secret")
+	_, err := s.Decrypt(encrypted, "Secret")
+	s.Require().Equal(ErrWrongPassphrase, err)
+}
+
+func (s *TestCryptSuite) TestNotEncrypted() {
+	_, err := s.Decrypt([]byte("plain data that is long enough to fill the whole header of the file"), "secret")
+	s.Require().Equal(ErrNotEncrypted, err)
+	_, err = s.Decrypt(nil, "secret")
+	s.Require().Equal(ErrNotEncrypted, err)
+}
+
+func (s *TestCryptSuite) TestModified() {
+	encrypted := s.Encrypt([]byte("data"), "secret")
+	encrypted[len(encrypted)-1] ^= 1
+	_, err := s.Decrypt(encrypted, "secret")
+	s.Require().Equal(ErrCorrupted, err)
+}
+
+func (s *TestCryptSuite) TestModifiedHeader() {
+	encrypted := s.Encrypt([]byte("data"), "secret")
+	encrypted[headerSize-1] ^= 1
+	_, err := s.Decrypt(encrypted, "secret")
+	s.Require().Equal(ErrWrongPassphrase, err)
+}
+
+func (s *TestCryptSuite) TestTruncated() {
+	data := make([]byte, 2*chunkSize+10)
+	encrypted := s.Encrypt(data, "secret")
+	chunk := chunkSize + 16
+	for _, size := range []int{headerSize + macSize, headerSize + macSize + chunk, len(encrypted) - 1} {
+		_, err := s.Decrypt(encrypted[:size], "secret")
+		s.Require().Equal(ErrCorrupted, err, size)
+	}
+}
+
+func (s *TestCryptSuite) TestIsEncrypted() {
+	encrypted, err := IsEncrypted(bufio.NewReader(bytes.NewReader(s.Encrypt(nil, "secret"))))
+	s.Require().NoError(err)
+	s.Require().True(encrypted)
+	encrypted, err = IsEncrypted(bufio.NewReader(bytes.NewReader([]byte{0x1f, 0x8b})))
+	s.Require().NoError(err)
+	s.Require().False(encrypted)
+}
+
+func TestCryptSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestCryptSuite))
+}
@@ -1,37 +1,194 @@
 package desktop
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 	"text/template"
 
+	"github.com/un-def/manygram/internal/profile"
 	"github.com/un-def/manygram/internal/util"
 )
 
-var entryTemplate = template.Must(template.New("Desktop Entry").Parse(`[Desktop Entry]
+var entryTemplate = template.Must(template.New("Desktop Entry").Funcs(template.FuncMap{
+	"escape": escape,
+}).Parse(`[Desktop Entry]
 Version=1.1
 Type=Application
-Name={{.Name}}
-Icon=telegram
-TryExec={{.TryExec}}
-Exec={{.Exec}}
+Name={{escape .Name}}
+{{with .Comment}}Comment={{escape .}}
+{{end -}}
+Icon={{escape .Icon}}
+TryExec={{escape .TryExec}}
+Exec={{escape .Exec}}
 Terminal=false
+{{if .NoDisplay}}NoDisplay=true
+{{end -}}
 Categories=Chat;Network;InstantMessaging;Qt;
 Keywords=tg;chat;im;messaging;messenger;sms;tdesktop;
-StartupWMClass=TelegramDesktop
+{{with .MimeTypes}}MimeType={{range .}}{{.}};{{end}}
+{{end -}}
+{{with .WMClass}}StartupWMClass={{.}}
+{{end -}}
 X-GNOME-UsesNotifications=true
+{{- if .Actions}}
+Actions={{range .Actions}}{{.ID}};{{end}}
+{{range .Actions}}
+[Desktop Action {{.ID}}]
+Name={{escape .Name}}
+Icon={{escape .Icon}}
+Exec={{escape .Exec}}
+{{end}}{{end}}
 `))
 
-type entryTemplateStruct struct {
-	Name    string
+// DefaultIcon is the icon name used if the entry has no icon
+const DefaultIcon = "telegram"
+
+// DefaultWMClass is the window class of Telegram Desktop
+const DefaultWMClass = "TelegramDesktop"
+
+// Entry represents the desktop entry of the profile
+type Entry struct {
+	// Profile is the profile name
+	Profile string
+	// Name is the application name, the default one is built from the profile name if empty
+	Name string
+	// Comment is the optional tooltip
+	Comment string
+	// Icon is the icon path or the icon theme name, DefaultIcon is used if empty
+	Icon string
+	// TryExec is the path or the name of the executable checked before showing the entry
 	TryExec string
-	Exec    string
+	// Exec is the command line, use ExecLine() to build it
+	Exec string
+	// WMClass is the window class used to match windows with the entry, DefaultWMClass is used if empty
+	WMClass string
+	// MimeTypes are the MIME types supported by the application
+	MimeTypes []string
+	// NoDisplay hides the entry from menus
+	NoDisplay bool
+}
+
+// CombinedName is the file name of the combined desktop entry
+const CombinedName = "manygram.desktop"
+
+// CombinedTitle is the application name of the combined desktop entry
+const CombinedTitle = "Telegram Desktop"
+
+// ErrNoEntries is returned by the CreateCombined() function when there are no entries to combine
+var ErrNoEntries = errors.New("no entries")
+
+type templateValues struct {
+	Entry
+	Actions []*action
+}
+
+type action struct {
+	ID   string
+	Name string
+	Icon string
+	Exec string
+}
+
+// actionID converts the profile name into a valid action identifier
+func actionID(profile string) string {
+	return strings.Replace(profile, "_", "-", -1)
+}
+
+var escapeReplacer = strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\t", "\\t", "\r", "\\r")
+
+// escape escapes the string value according to the Desktop Entry Specification
+func escape(value string) string {
+	return escapeReplacer.Replace(value)
+}
+
+// execReservedChars are the characters requiring the argument to be quoted
+const execReservedChars = " \t\n\"'\\><~|&;$*?#()`"
+
+var execQuoteReplacer = strings.NewReplacer(`"`, `\"`, "`", "\\`", "$", `\$`, `\`, `\\`)
+
+// ExecLine builds the value of the Exec key quoting arguments according to the Desktop Entry Specification
+func ExecLine(args ...string) string {
+	quoted := make([]string, len(args))
+	for idx, arg := range args {
+		arg = strings.Replace(arg, "%", "%%", -1)
+		if arg == "" || strings.ContainsAny(arg, execReservedChars) {
+			arg = `"` + execQuoteReplacer.Replace(arg) + `"`
+		}
+		quoted[idx] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// ReadTryExec reads the unescaped value of the TryExec key of the desktop entry,
+// an empty string is returned if there is no such key
+func ReadTryExec(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	group := ""
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			group = line
+			continue
+		}
+		if group != "[Desktop Entry]" {
+			continue
+		}
+		idx := strings.Index(line, "=")
+		if idx != -1 && strings.TrimSpace(line[:idx]) == "TryExec" {
+			return unescape(strings.TrimSpace(line[idx+1:])), nil
+		}
+	}
+	return "", nil
+}
+
+// unescape reverts escape()
+func unescape(value string) string {
+	var builder strings.Builder
+	for idx := 0; idx < len(value); idx++ {
+		if value[idx] != '\\' || idx == len(value)-1 {
+			builder.WriteByte(value[idx])
+			continue
+		}
+		idx++
+		switch value[idx] {
+		case 'n':
+			builder.WriteByte('\n')
+		case 't':
+			builder.WriteByte('\t')
+		case 'r':
+			builder.WriteByte('\r')
+		case 's':
+			builder.WriteByte(' ')
+		default:
+			builder.WriteByte(value[idx])
+		}
+	}
+	return builder.String()
 }
 
+const (
+	entryPrefix = "telegramdesktop."
+	entrySuffix = ".desktop"
+)
+
 // Path builds a path to the desktop entry
 func Path(dir, name string) string {
-	return path.Join(dir, fmt.Sprintf("telegramdesktop.%s.desktop", name))
+	return path.Join(dir, entryPrefix+name+entrySuffix)
+}
+
+// List returns profile names of all desktop entries in the directory,
+// entries with invalid profile names are skipped as they are not created by manygram
+func List(dir string) ([]string, error) {
+	return listNames(dir, entryPrefix, entrySuffix)
 }
 
 // Exist checks whether the desktop entry exists
@@ -39,19 +196,221 @@ func Exist(dir, name string) (bool, error) {
 	return util.Exist(Path(dir, name))
 }
 
-// Create creates a new desktop entry
-func Create(dir, name, tryExec, exec string) error {
-	path := Path(dir, name)
-	file, err := os.Create(path)
+// listNames returns the variable parts of the file names with the prefix and the suffix being valid profile names
+func listNames(dir, prefix, suffix string) ([]string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, file := range files {
+		fileName := file.Name()
+		if file.IsDir() || len(fileName) <= len(prefix)+len(suffix) {
+			continue
+		}
+		if !strings.HasPrefix(fileName, prefix) || !strings.HasSuffix(fileName, suffix) {
+			continue
+		}
+		if name := fileName[len(prefix) : len(fileName)-len(suffix)]; profile.IsValidName(name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Create creates a new desktop entry or rewrites the existing one
+func Create(dir string, entry *Entry) error {
+	return write(Path(dir, entry.Profile), newValues(entry))
+}
+
+// IsStale checks whether the existing desktop entry differs from the one Create() would write
+func IsStale(dir string, entry *Entry) (bool, error) {
+	return isStale(Path(dir, entry.Profile), newValues(entry))
+}
+
+func newValues(entry *Entry) *templateValues {
+	values := &templateValues{Entry: *entry}
+	if values.Name == "" {
+		values.Name = fmt.Sprintf("Telegram Desktop – %s", entry.Profile)
+	}
+	if values.Icon == "" {
+		values.Icon = DefaultIcon
+	}
+	if values.WMClass == "" {
+		values.WMClass = DefaultWMClass
+	}
+	return values
+}
+
+const autostartPrefix = "manygram-"
+
+// AutostartPath builds a path to the autostart entry of the profile
+func AutostartPath(dir, name string) string {
+	return path.Join(dir, autostartPrefix+name+entrySuffix)
+}
+
+// AutostartExist checks whether the autostart entry exists
+func AutostartExist(dir, name string) (bool, error) {
+	return util.Exist(AutostartPath(dir, name))
+}
+
+// ListAutostart returns profile names of all autostart entries in the directory,
+// entries with invalid profile names are skipped
+func ListAutostart(dir string) ([]string, error) {
+	return listNames(dir, autostartPrefix, entrySuffix)
+}
+
+// CreateAutostart creates a new autostart entry or rewrites the existing one,
+// the entry is the same as the desktop entry but is stored in the autostart directory
+func CreateAutostart(dir string, entry *Entry) error {
+	return write(AutostartPath(dir, entry.Profile), newValues(entry))
+}
+
+// IsAutostartStale checks whether the existing autostart entry differs from the one CreateAutostart() would write
+func IsAutostartStale(dir string, entry *Entry) (bool, error) {
+	return isStale(AutostartPath(dir, entry.Profile), newValues(entry))
+}
+
+// RemoveAutostart removes the autostart entry
+func RemoveAutostart(dir, name string) error {
+	return os.Remove(AutostartPath(dir, name))
+}
+
+// CombinedPath builds a path to the combined desktop entry
+func CombinedPath(dir string) string {
+	return path.Join(dir, CombinedName)
+}
+
+// CombinedExist checks whether the combined desktop entry exists
+func CombinedExist(dir string) (bool, error) {
+	return util.Exist(CombinedPath(dir))
+}
+
+// CreateCombined creates or rewrites the combined desktop entry with an action for every entry,
+// the entry itself launches the first one
+func CreateCombined(dir string, entries []*Entry) error {
+	if len(entries) == 0 {
+		return ErrNoEntries
+	}
+	return write(CombinedPath(dir), newCombinedValues(entries))
+}
+
+// IsCombinedStale checks whether the existing combined desktop entry differs
+// from the one CreateCombined() would write
+func IsCombinedStale(dir string, entries []*Entry) (bool, error) {
+	if len(entries) == 0 {
+		return false, ErrNoEntries
+	}
+	return isStale(CombinedPath(dir), newCombinedValues(entries))
+}
+
+func newCombinedValues(entries []*Entry) *templateValues {
+	first := entries[0]
+	values := &templateValues{Entry: Entry{
+		Name:    CombinedTitle,
+		Icon:    DefaultIcon,
+		TryExec: first.TryExec,
+		Exec:    first.Exec,
+	}}
+	for _, entry := range entries {
+		act := &action{
+			ID:   actionID(entry.Profile),
+			Name: entry.Name,
+			Icon: entry.Icon,
+			Exec: entry.Exec,
+		}
+		if act.Name == "" {
+			act.Name = entry.Profile
+		}
+		if act.Icon == "" {
+			act.Icon = DefaultIcon
+		}
+		values.Actions = append(values.Actions, act)
+	}
+	return values
+}
+
+// RemoveCombined removes the combined desktop entry
+func RemoveCombined(dir string) error {
+	return os.Remove(CombinedPath(dir))
+}
+
+// HandlerName is the file name of the URL handler desktop entry
+const HandlerName = "manygram-handler.desktop"
+
+// HandlerTitle is the application name of the URL handler desktop entry
+const HandlerTitle = "Telegram Desktop (manygram)"
+
+// HandlerMimeType is the MIME type of tg:// links
+const HandlerMimeType = "x-scheme-handler/tg"
+
+// HandlerPath builds a path to the URL handler desktop entry
+func HandlerPath(dir string) string {
+	return path.Join(dir, HandlerName)
+}
+
+// HandlerExist checks whether the URL handler desktop entry exists
+func HandlerExist(dir string) (bool, error) {
+	return util.Exist(HandlerPath(dir))
+}
+
+// CreateHandler creates or rewrites the hidden desktop entry handling tg:// links,
+// Exec of the entry should contain %u field code replaced with the URL
+func CreateHandler(dir string, tryExec string, exec string) error {
+	return write(HandlerPath(dir), newHandlerValues(tryExec, exec))
+}
+
+// IsHandlerStale checks whether the existing URL handler desktop entry differs
+// from the one CreateHandler() would write
+func IsHandlerStale(dir string, tryExec string, exec string) (bool, error) {
+	return isStale(HandlerPath(dir), newHandlerValues(tryExec, exec))
+}
+
+func newHandlerValues(tryExec string, exec string) *templateValues {
+	return &templateValues{Entry: Entry{
+		Name:      HandlerTitle,
+		Icon:      DefaultIcon,
+		TryExec:   tryExec,
+		Exec:      exec,
+		MimeTypes: []string{HandlerMimeType},
+		NoDisplay: true,
+	}}
+}
+
+// RemoveHandler removes the URL handler desktop entry
+func RemoveHandler(dir string) error {
+	return os.Remove(HandlerPath(dir))
+}
+
+func render(values *templateValues) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := entryTemplate.Execute(&buf, values); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func write(path string, values *templateValues) error {
+	content, err := render(values)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-	return entryTemplate.Execute(file, entryTemplateStruct{
-		Name:    fmt.Sprintf("Telegram Desktop – %s", name),
-		TryExec: tryExec,
-		Exec:    exec,
-	})
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+func isStale(path string, values *templateValues) (bool, error) {
+	content, err := render(values)
+	if err != nil {
+		return false, err
+	}
+	current, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(current, content), nil
 }
 
 // Remove removes the desktop entry
@@ -49,17 +49,56 @@ func (s *TestDesktopSuite) TestExist() {
 	s.Require().True(exist)
 }
 
-func (s *TestDesktopSuite) TestCreate() {
-	err := Create(s.dir, profileName, tryExec, exec)
-	s.Require().NoError(err)
+func (s *TestDesktopSuite) Read() string {
 	s.Require().FileExists(s.path)
 	contentByte, err := ioutil.ReadFile(s.path)
 	s.Require().NoError(err)
-	content := string(contentByte)
+	return string(contentByte)
+}
+
+func (s *TestDesktopSuite) TestCreate() {
+	err := Create(s.dir, &Entry{Profile: profileName, TryExec: tryExec, Exec: exec})
+	s.Require().NoError(err)
+	content := s.Read()
 	name := fmt.Sprintf("Telegram Desktop – %s", profileName)
-	s.Require().Contains(content, "Name="+name)
-	s.Require().Contains(content, "TryExec="+tryExec)
-	s.Require().Contains(content, "Exec="+exec)
+	s.Require().Contains(content, "\nName="+name+"\n")
+	s.Require().Contains(content, "\nIcon=telegram\n")
+	s.Require().Contains(content, "\nTryExec="+tryExec+"\n")
+	s.Require().Contains(content, "\nExec="+exec+"\n")
+	s.Require().Contains(content, "\nStartupWMClass=TelegramDesktop\n")
+	s.Require().NotContains(content, "Comment=")
+}
+
+func (s *TestDesktopSuite) TestCreateMetadata() {
+	err := Create(s.dir, &Entry{
+		Profile: profileName,
+		Name:    "Работа 💼",
+		Comment: "Support desk\nsecond line",
+		Icon:    "/path/to/icon.png",
+		TryExec: tryExec,
+		Exec:    exec,
+		WMClass: "manygram-foo",
+	})
+	s.Require().NoError(err)
+	content := s.Read()
+	s.Require().Contains(content, "\nStartupWMClass=manygram-foo\n")
+	s.Require().Contains(content, "\nName=Работа 💼\n")
+	s.Require().Contains(content, "\nComment=Support desk\\nsecond line\n")
+	s.Require().Contains(content, "\nIcon=/path/to/icon.png\n")
+}
+
+func (s *TestDesktopSuite) TestCreateDirNotExist() {
+	dir := path.Join(s.dir, "applications")
+	err := Create(dir, &Entry{Profile: profileName, TryExec: tryExec, Exec: exec})
+	s.Require().NoError(err)
+	s.Require().FileExists(Path(dir, profileName))
+}
+
+func (s *TestDesktopSuite) TestCreateRewrite() {
+	s.Create()
+	err := Create(s.dir, &Entry{Profile: profileName, TryExec: tryExec, Exec: exec})
+	s.Require().NoError(err)
+	s.Require().Contains(s.Read(), "[Desktop Entry]")
 }
 
 func (s *TestDesktopSuite) TestRemoveErrNotExist() {
@@ -75,6 +114,155 @@ func (s *TestDesktopSuite) TestRemoveOK() {
 	s.Require().NoFileExists(s.path)
 }
 
+func (s *TestDesktopSuite) TestCreateCombined() {
+	err := CreateCombined(s.dir, []*Entry{
+		{Profile: "foo", TryExec: tryExec, Exec: "false run foo", Icon: "manygram-foo"},
+		{Profile: "bar_baz", Name: "Работа", TryExec: tryExec, Exec: "false run bar_baz"},
+	})
+	s.Require().NoError(err)
+	exist, err := CombinedExist(s.dir)
+	s.Require().NoError(err)
+	s.Require().True(exist)
+	contentByte, err := ioutil.ReadFile(path.Join(s.dir, "manygram.desktop"))
+	s.Require().NoError(err)
+	content := string(contentByte)
+	s.Require().Contains(content, "\nName=Telegram Desktop\n")
+	s.Require().Contains(content, "\nExec=false run foo\n")
+	s.Require().NotContains(content, "StartupWMClass=")
+	s.Require().Contains(content, "\nActions=foo;bar-baz;\n")
+	s.Require().Contains(content, "\n[Desktop Action foo]\nName=foo\nIcon=manygram-foo\nExec=false run foo\n")
+	s.Require().Contains(content, "\n[Desktop Action bar-baz]\nName=Работа\nIcon=telegram\nExec=false run bar_baz\n")
+	s.Require().NoError(RemoveCombined(s.dir))
+	exist, err = CombinedExist(s.dir)
+	s.Require().NoError(err)
+	s.Require().False(exist)
+}
+
+func (s *TestDesktopSuite) TestCreateCombinedErrNoEntries() {
+	err := CreateCombined(s.dir, nil)
+	s.Require().True(errors.Is(err, ErrNoEntries), err)
+}
+
+func (s *TestDesktopSuite) TestList() {
+	s.Create()
+	for _, name := range []string{"telegramdesktop.bar.desktop", "telegramdesktop.desktop", "telegramdesktop.b:z.desktop", "manygram.desktop", "other.desktop"} {
+		s.Require().NoError(ioutil.WriteFile(path.Join(s.dir, name), nil, 0644))
+	}
+	s.Require().NoError(os.Mkdir(path.Join(s.dir, "telegramdesktop.baz.desktop"), 0755))
+	names, err := List(s.dir)
+	s.Require().NoError(err)
+	s.Require().ElementsMatch([]string{"foo", "bar"}, names)
+}
+
+func (s *TestDesktopSuite) TestListErrNotExist() {
+	_, err := List(path.Join(s.dir, "missing"))
+	s.Require().True(errors.Is(err, os.ErrNotExist), err)
+}
+
+func (s *TestDesktopSuite) TestIsStale() {
+	entry := &Entry{Profile: profileName, TryExec: tryExec, Exec: exec}
+	s.Require().NoError(Create(s.dir, entry))
+	stale, err := IsStale(s.dir, entry)
+	s.Require().NoError(err)
+	s.Require().False(stale)
+	entry.Comment = "Work account"
+	stale, err = IsStale(s.dir, entry)
+	s.Require().NoError(err)
+	s.Require().True(stale)
+}
+
+func (s *TestDesktopSuite) TestIsStaleErrNotExist() {
+	_, err := IsStale(s.dir, &Entry{Profile: profileName, TryExec: tryExec, Exec: exec})
+	s.Require().True(errors.Is(err, os.ErrNotExist), err)
+}
+
+func (s *TestDesktopSuite) TestIsCombinedStale() {
+	entries := []*Entry{{Profile: "foo", TryExec: tryExec, Exec: "false run foo"}}
+	s.Require().NoError(CreateCombined(s.dir, entries))
+	stale, err := IsCombinedStale(s.dir, entries)
+	s.Require().NoError(err)
+	s.Require().False(stale)
+	entries = append(entries, &Entry{Profile: "bar", TryExec: tryExec, Exec: "false run bar"})
+	stale, err = IsCombinedStale(s.dir, entries)
+	s.Require().NoError(err)
+	s.Require().True(stale)
+}
+
+func (s *TestDesktopSuite) TestExecLine() {
+	s.Require().Equal("/usr/bin/manygram run foo", ExecLine("/usr/bin/manygram", "run", "foo"))
+	s.Require().Equal(
+		"\"/home/user/my apps/manygram\" run \"\" \"100%% \\$\\\"\\`\\\\\"",
+		ExecLine("/home/user/my apps/manygram", "run", "", "100% $\"`\\"),
+	)
+}
+
+func (s *TestDesktopSuite) TestExecLineEscaped() {
+	err := Create(s.dir, &Entry{Profile: profileName, TryExec: `/opt/a\b`, Exec: ExecLine(`/opt/a\b`, "run")})
+	s.Require().NoError(err)
+	content := s.Read()
+	s.Require().Contains(content, "\nTryExec=/opt/a\\\\b\n")
+	s.Require().Contains(content, "\nExec=\"/opt/a\\\\\\\\b\" run\n")
+}
+
+func (s *TestDesktopSuite) TestReadTryExec() {
+	err := Create(s.dir, &Entry{Profile: profileName, TryExec: "/opt/my apps/a\\b", Exec: exec})
+	s.Require().NoError(err)
+	tryExec, err := ReadTryExec(s.path)
+	s.Require().NoError(err)
+	s.Require().Equal("/opt/my apps/a\\b", tryExec)
+}
+
+func (s *TestDesktopSuite) TestReadTryExecNoKey() {
+	s.Require().NoError(ioutil.WriteFile(s.path, []byte("[Desktop Entry]\nExec=foo\n[Other]\nTryExec=bar\n"), 0644))
+	tryExec, err := ReadTryExec(s.path)
+	s.Require().NoError(err)
+	s.Require().Equal("", tryExec)
+}
+
+func (s *TestDesktopSuite) TestCreateHandler() {
+	err := CreateHandler(s.dir, tryExec, "false open -- %u")
+	s.Require().NoError(err)
+	exist, err := HandlerExist(s.dir)
+	s.Require().NoError(err)
+	s.Require().True(exist)
+	contentByte, err := ioutil.ReadFile(path.Join(s.dir, "manygram-handler.desktop"))
+	s.Require().NoError(err)
+	content := string(contentByte)
+	s.Require().Contains(content, "\nExec=false open -- %u\n")
+	s.Require().Contains(content, "\nNoDisplay=true\n")
+	s.Require().Contains(content, "\nMimeType=x-scheme-handler/tg;\n")
+	s.Require().NotContains(content, "StartupWMClass=")
+	stale, err := IsHandlerStale(s.dir, tryExec, "false open -- %u")
+	s.Require().NoError(err)
+	s.Require().False(stale)
+	s.Require().NoError(RemoveHandler(s.dir))
+	exist, err = HandlerExist(s.dir)
+	s.Require().NoError(err)
+	s.Require().False(exist)
+}
+
+func (s *TestDesktopSuite) TestAutostart() {
+	entry := &Entry{Profile: profileName, TryExec: tryExec, Exec: exec + " -- -autostart"}
+	s.Require().NoError(CreateAutostart(s.dir, entry))
+	exist, err := AutostartExist(s.dir, profileName)
+	s.Require().NoError(err)
+	s.Require().True(exist)
+	contentByte, err := ioutil.ReadFile(path.Join(s.dir, "manygram-foo.desktop"))
+	s.Require().NoError(err)
+	s.Require().Contains(string(contentByte), "\nExec=false run foo -- -autostart\n")
+	stale, err := IsAutostartStale(s.dir, entry)
+	s.Require().NoError(err)
+	s.Require().False(stale)
+	s.Require().NoError(ioutil.WriteFile(path.Join(s.dir, "manygram-handler.desktop"), nil, 0644))
+	names, err := ListAutostart(s.dir)
+	s.Require().NoError(err)
+	s.Require().ElementsMatch([]string{"foo", "handler"}, names)
+	s.Require().NoError(RemoveAutostart(s.dir, profileName))
+	exist, err = AutostartExist(s.dir, profileName)
+	s.Require().NoError(err)
+	s.Require().False(exist)
+}
+
 func TestDesktopSuiteTest(t *testing.T) {
 	suite.Run(t, new(TestDesktopSuite))
 }
@@ -0,0 +1,329 @@
+package icon
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Style of the generated icon
+type Style string
+
+// Supported icon styles
+const (
+	// StyleTelegram is Telegram logo with a badge in the profile color
+	StyleTelegram Style = "telegram"
+	// StyleCircle is a plain circle in the profile color
+	StyleCircle Style = "circle"
+)
+
+// DefaultColor is the color used if the profile has no color
+const DefaultColor = "#2AABEE"
+
+const telegramColor = "#2AABEE"
+
+// PNGSizes are sizes of PNG variants of the icon
+var PNGSizes = []int{16, 32, 48, 64, 128, 256}
+
+const svgSize = 256
+
+// supersampling factor used for antialiasing
+const samples = 4
+
+// paper plane of Telegram logo, coordinates are relative to the icon size
+var plane = []point{
+	{0.23, 0.50}, {0.75, 0.29}, {0.67, 0.73}, {0.52, 0.63},
+	{0.44, 0.71}, {0.42, 0.58}, {0.64, 0.38}, {0.38, 0.55},
+}
+
+type point struct {
+	x, y float64
+}
+
+// Icon describes the generated profile icon
+type Icon struct {
+	Profile string
+	Letter  rune
+	Color   string
+	Style   Style
+}
+
+// Name returns the icon theme name of the profile icon
+func Name(profile string) string {
+	return "manygram-" + profile
+}
+
+// Letter returns the uppercased first letter of the first candidate
+// that can be rendered, or '?' if there is no such candidate
+func Letter(candidates ...string) rune {
+	for _, candidate := range candidates {
+		for _, r := range strings.TrimSpace(candidate) {
+			r = unicode.ToUpper(r)
+			if _, ok := glyphs[r]; ok {
+				return r
+			}
+			break
+		}
+	}
+	return '?'
+}
+
+func svgPath(dir string, profile string) string {
+	return path.Join(dir, "hicolor", "scalable", "apps", Name(profile)+".svg")
+}
+
+func pngPath(dir string, profile string, size int) string {
+	sizeDir := fmt.Sprintf("%dx%d", size, size)
+	return path.Join(dir, "hicolor", sizeDir, "apps", Name(profile)+".png")
+}
+
+// Generate writes SVG and PNG variants of the icon into the icon theme directory
+// (e.g., $XDG_DATA_HOME/icons)
+func Generate(dir string, icon *Icon) error {
+	layout, err := newLayout(icon)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(svgPath(dir, icon.Profile), layout.svg()); err != nil {
+		return err
+	}
+	for _, size := range PNGSizes {
+		buf := new(bytes.Buffer)
+		if err := png.Encode(buf, layout.raster(size)); err != nil {
+			return err
+		}
+		if err := writeFile(pngPath(dir, icon.Profile, size), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove removes all variants of the profile icon, missing files are ignored
+func Remove(dir string, profile string) error {
+	paths := []string{svgPath(dir, profile)}
+	for _, size := range PNGSizes {
+		paths = append(paths, pngPath(dir, profile, size))
+	}
+	for _, filePath := range paths {
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(filePath string, content []byte) error {
+	if err := os.MkdirAll(path.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filePath, content, 0644)
+}
+
+type circle struct {
+	center point
+	r      float64
+	color  color.NRGBA
+}
+
+func (c *circle) contains(p point) bool {
+	dx, dy := p.x-c.center.x, p.y-c.center.y
+	return dx*dx+dy*dy <= c.r*c.r
+}
+
+// layout is a list of shapes drawn in order, coordinates are relative to the icon size
+type layout struct {
+	circles     []circle
+	plane       bool
+	letter      rune
+	letterBox   point
+	letterCell  float64
+	letterColor color.NRGBA
+}
+
+func newLayout(icon *Icon) (*layout, error) {
+	colorValue := icon.Color
+	if colorValue == "" {
+		colorValue = DefaultColor
+	}
+	profileColor, err := parseColor(colorValue)
+	if err != nil {
+		return nil, err
+	}
+	white := color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	l := &layout{letter: icon.Letter, letterColor: contrastColor(profileColor)}
+	var badge *circle
+	switch icon.Style {
+	case StyleCircle:
+		badge = &circle{point{0.5, 0.5}, 0.5, profileColor}
+		l.circles = []circle{*badge}
+	case StyleTelegram, "":
+		base, _ := parseColor(telegramColor)
+		badge = &circle{point{0.74, 0.74}, 0.22, profileColor}
+		l.circles = []circle{
+			{point{0.5, 0.5}, 0.5, base},
+			{badge.center, 0.26, white},
+			*badge,
+		}
+		l.plane = true
+	default:
+		return nil, fmt.Errorf("unknown icon style %q", icon.Style)
+	}
+	// the letter occupies 55% of the badge diameter in height
+	height := badge.r * 2 * 0.55
+	l.letterCell = height / glyphHeight
+	l.letterBox = point{
+		badge.center.x - l.letterCell*glyphWidth/2,
+		badge.center.y - height/2,
+	}
+	return l, nil
+}
+
+func (l *layout) colorAt(p point) color.NRGBA {
+	var c color.NRGBA
+	for idx, circ := range l.circles {
+		if circ.contains(p) {
+			c = circ.color
+		}
+		// the plane is drawn over the base circle but under the badge
+		if idx == 0 && l.plane && inPolygon(p, plane) {
+			c = color.NRGBA{0xff, 0xff, 0xff, 0xff}
+		}
+	}
+	if l.inLetter(p) {
+		c = l.letterColor
+	}
+	return c
+}
+
+func (l *layout) inLetter(p point) bool {
+	glyph, ok := glyphs[l.letter]
+	if !ok {
+		return false
+	}
+	col := int((p.x - l.letterBox.x) / l.letterCell)
+	row := int((p.y - l.letterBox.y) / l.letterCell)
+	if p.x < l.letterBox.x || p.y < l.letterBox.y || col >= glyphWidth || row >= glyphHeight {
+		return false
+	}
+	return glyph[row][col] == '#'
+}
+
+func (l *layout) raster(size int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	step := 1 / float64(size*samples)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			var r, g, b, a float64
+			for sy := 0; sy < samples; sy++ {
+				for sx := 0; sx < samples; sx++ {
+					p := point{
+						(float64(x*samples+sx) + 0.5) * step,
+						(float64(y*samples+sy) + 0.5) * step,
+					}
+					c := l.colorAt(p)
+					alpha := float64(c.A) / 0xff
+					r += float64(c.R) * alpha
+					g += float64(c.G) * alpha
+					b += float64(c.B) * alpha
+					a += alpha
+				}
+			}
+			if a == 0 {
+				continue
+			}
+			img.SetNRGBA(x, y, color.NRGBA{
+				uint8(r/a + 0.5),
+				uint8(g/a + 0.5),
+				uint8(b/a + 0.5),
+				uint8(a/(samples*samples)*0xff + 0.5),
+			})
+		}
+	}
+	return img
+}
+
+func (l *layout) svg() []byte {
+	buf := new(bytes.Buffer)
+	scale := func(v float64) string {
+		return strconv.FormatFloat(v*svgSize, 'f', -1, 64)
+	}
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %[1]d %[2]d">`+"\n", svgSize, svgSize)
+	for idx, circ := range l.circles {
+		fmt.Fprintf(
+			buf, `  <circle cx="%s" cy="%s" r="%s" fill="%s"/>`+"\n",
+			scale(circ.center.x), scale(circ.center.y), scale(circ.r), formatColor(circ.color),
+		)
+		if idx == 0 && l.plane {
+			points := make([]string, len(plane))
+			for pidx, p := range plane {
+				points[pidx] = scale(p.x) + "," + scale(p.y)
+			}
+			fmt.Fprintf(buf, `  <polygon points="%s" fill="#FFFFFF"/>`+"\n", strings.Join(points, " "))
+		}
+	}
+	if glyph, ok := glyphs[l.letter]; ok {
+		fmt.Fprintf(buf, `  <g fill="%s">`+"\n", formatColor(l.letterColor))
+		for row, line := range glyph {
+			for col, pixel := range line {
+				if pixel != '#' {
+					continue
+				}
+				fmt.Fprintf(
+					buf, `    <rect x="%s" y="%s" width="%s" height="%[3]s"/>`+"\n",
+					scale(l.letterBox.x+float64(col)*l.letterCell),
+					scale(l.letterBox.y+float64(row)*l.letterCell),
+					scale(l.letterCell),
+				)
+			}
+		}
+		buf.WriteString("  </g>\n")
+	}
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
+
+// inPolygon checks whether the point is inside the polygon using the even-odd rule
+func inPolygon(p point, polygon []point) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.y > p.y) != (b.y > p.y) && p.x < (b.x-a.x)*(p.y-a.y)/(b.y-a.y)+a.x {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func parseColor(value string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 || !strings.HasPrefix(value, "#") {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q", value)
+	}
+	return color.NRGBA{uint8(n >> 16), uint8(n >> 8), uint8(n), 0xff}, nil
+}
+
+func formatColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
+
+// contrastColor returns white for dark colors and almost black for light ones
+func contrastColor(c color.NRGBA) color.NRGBA {
+	luminance := 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+	if luminance > 186 {
+		return color.NRGBA{0x21, 0x21, 0x21, 0xff}
+	}
+	return color.NRGBA{0xff, 0xff, 0xff, 0xff}
+}
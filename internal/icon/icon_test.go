@@ -0,0 +1,93 @@
+package icon
+
+import (
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestIconSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *TestIconSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "test-icon-*")
+	s.Require().NoError(err)
+	s.dir = dir
+}
+
+func (s *TestIconSuite) TearDownTest() {
+	err := os.RemoveAll(s.dir)
+	s.Require().NoError(err)
+}
+
+func (s *TestIconSuite) TestName() {
+	s.Require().Equal("manygram-foo", Name("foo"))
+}
+
+func (s *TestIconSuite) TestLetter() {
+	s.Require().Equal('W', Letter("work account", "foo"))
+	s.Require().Equal('F', Letter("", "foo"))
+	s.Require().Equal('F', Letter("  Работа", "foo"))
+	s.Require().Equal('?', Letter("", "💼"))
+}
+
+func (s *TestIconSuite) TestGenerate() {
+	for _, style := range []Style{StyleTelegram, StyleCircle, ""} {
+		err := Generate(s.dir, &Icon{Profile: "foo", Letter: 'F', Color: "#E53935", Style: style})
+		s.Require().NoError(err)
+		svg, err := ioutil.ReadFile(path.Join(s.dir, "hicolor/scalable/apps/manygram-foo.svg"))
+		s.Require().NoError(err)
+		s.Require().Contains(string(svg), `fill="#E53935"`)
+		for _, size := range PNGSizes {
+			file, err := os.Open(pngPath(s.dir, "foo", size))
+			s.Require().NoError(err)
+			config, err := png.DecodeConfig(file)
+			file.Close()
+			s.Require().NoError(err)
+			s.Require().Equal(size, config.Width)
+			s.Require().Equal(size, config.Height)
+		}
+	}
+}
+
+func (s *TestIconSuite) TestGenerateColors() {
+	err := Generate(s.dir, &Icon{Profile: "foo", Letter: 'F', Color: "#E53935", Style: StyleCircle})
+	s.Require().NoError(err)
+	file, err := os.Open(pngPath(s.dir, "foo", 64))
+	s.Require().NoError(err)
+	defer file.Close()
+	img, err := png.Decode(file)
+	s.Require().NoError(err)
+	r, g, b, a := img.At(32, 4).RGBA()
+	s.Require().Equal([]uint32{0xe5, 0x39, 0x35, 0xff}, []uint32{r >> 8, g >> 8, b >> 8, a >> 8})
+	_, _, _, a = img.At(0, 0).RGBA()
+	s.Require().Equal(uint32(0), a)
+}
+
+func (s *TestIconSuite) TestGenerateErrors() {
+	err := Generate(s.dir, &Icon{Profile: "foo", Letter: 'F', Color: "red"})
+	s.Require().Error(err)
+	err = Generate(s.dir, &Icon{Profile: "foo", Letter: 'F', Style: "square"})
+	s.Require().Error(err)
+}
+
+func (s *TestIconSuite) TestRemove() {
+	err := Generate(s.dir, &Icon{Profile: "foo", Letter: 'F'})
+	s.Require().NoError(err)
+	s.Require().NoError(Remove(s.dir, "foo"))
+	s.Require().NoFileExists(svgPath(s.dir, "foo"))
+	for _, size := range PNGSizes {
+		s.Require().NoFileExists(pngPath(s.dir, "foo", size))
+	}
+	s.Require().NoError(Remove(s.dir, "foo"))
+}
+
+func TestIconSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestIconSuite))
+}
@@ -0,0 +1,208 @@
+package logfile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Default values of the rotation parameters
+const (
+	DefaultMaxSize  = 10 * 1024 * 1024
+	DefaultMaxFiles = 3
+)
+
+// Writer is a log file rotated when its size exceeds MaxSize,
+// rotated files are named PATH.1 (the newest one), PATH.2 and so on
+type Writer struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+}
+
+// Open opens the log file for appending, the file is rotated first if it is already too big,
+// maxFiles is the number of rotated files kept in addition to the current one
+func Open(path string, maxSize int64, maxFiles int) (*Writer, error) {
+	w := &Writer{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil && info.Size() >= maxSize {
+		if err := Rotate(path, maxFiles); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) open() error {
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// Write writes to the log file rotating it if needed, it is safe for concurrent use
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	if err := Rotate(w.path, w.maxFiles); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+// Close closes the log file
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// Rotate renames the log file to PATH.1 shifting the existing rotated files
+// and removing the ones exceeding maxFiles
+func Rotate(path string, maxFiles int) error {
+	if maxFiles < 1 {
+		return os.Remove(path)
+	}
+	for idx := maxFiles; idx >= 1; idx-- {
+		src := rotatedPath(path, idx-1)
+		if err := os.Rename(src, rotatedPath(path, idx)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func rotatedPath(path string, idx int) string {
+	if idx == 0 {
+		return path
+	}
+	return fmt.Sprintf("%s.%d", path, idx)
+}
+
+// Files returns the paths of the existing log file and its rotated files from the newest to the oldest
+func Files(path string) ([]string, error) {
+	var files []string
+	for idx := 0; ; idx++ {
+		file := rotatedPath(path, idx)
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			if idx == 0 {
+				continue
+			}
+			return files, nil
+		} else if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+}
+
+// Tail returns last n lines of the log file, the whole file is returned if n <= 0
+func Tail(path string, n int) ([]byte, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil || n <= 0 {
+		return content, err
+	}
+	end := len(content)
+	if end > 0 && content[end-1] == '\n' {
+		end--
+	}
+	for idx := 0; idx < n; idx++ {
+		pos := bytes.LastIndexByte(content[:end], '\n')
+		if pos == -1 {
+			return content, nil
+		}
+		end = pos
+	}
+	return content[end+1:], nil
+}
+
+// Follow writes data appended to the log file to w until the stop channel is closed,
+// the file is reopened when it is rotated or truncated
+func Follow(path string, offset int64, w io.Writer, interval time.Duration, stop <-chan struct{}) error {
+	var file *os.File
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+	var info os.FileInfo
+	for {
+		current, err := os.Stat(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if current != nil && (file == nil || !os.SameFile(info, current) || current.Size() < offset) {
+			if file != nil {
+				// read the rest of the rotated file
+				if _, err := io.Copy(w, file); err != nil {
+					return err
+				}
+				file.Close()
+				offset = 0
+			}
+			if file, err = os.Open(path); err != nil {
+				return err
+			}
+			if info, err = file.Stat(); err != nil {
+				return err
+			}
+			if offset > info.Size() {
+				offset = 0
+			}
+			if _, err := file.Seek(offset, io.SeekStart); err != nil {
+				return err
+			}
+		}
+		if file != nil {
+			n, err := io.Copy(w, file)
+			if err != nil {
+				return err
+			}
+			offset += n
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package logfile
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestLogFileSuite struct {
+	suite.Suite
+	dir  string
+	path string
+}
+
+func (s *TestLogFileSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "test-logfile-*")
+	s.Require().NoError(err)
+	s.dir = dir
+	s.path = path.Join(dir, "logs", "foo.log")
+}
+
+func (s *TestLogFileSuite) TearDownTest() {
+	err := os.RemoveAll(s.dir)
+	s.Require().NoError(err)
+}
+
+func (s *TestLogFileSuite) Read(path string) string {
+	content, err := ioutil.ReadFile(path)
+	s.Require().NoError(err)
+	return string(content)
+}
+
+func (s *TestLogFileSuite) Write(w *Writer, data string) {
+	_, err := w.Write([]byte(data))
+	s.Require().NoError(err)
+}
+
+func (s *TestLogFileSuite) TestWrite() {
+	w, err := Open(s.path, 10, 2)
+	s.Require().NoError(err)
+	s.Write(w, "12345")
+	s.Write(w, "67890")
+	s.Write(w, "abc")
+	s.Write(w, "defgh")
+	s.Write(w, "ijk")
+	s.Require().NoError(w.Close())
+	s.Require().Equal("ijk", s.Read(s.path))
+	s.Require().Equal("abcdefgh", s.Read(s.path+".1"))
+	s.Require().Equal("1234567890", s.Read(s.path+".2"))
+	files, err := Files(s.path)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{s.path, s.path + ".1", s.path + ".2"}, files)
+}
+
+func (s *TestLogFileSuite) TestMaxFiles() {
+	w, err := Open(s.path, 3, 1)
+	s.Require().NoError(err)
+	for _, data := range []string{"aaa", "bbb", "ccc"} {
+		s.Write(w, data)
+	}
+	s.Require().NoError(w.Close())
+	s.Require().Equal("ccc", s.Read(s.path))
+	s.Require().Equal("bbb", s.Read(s.path+".1"))
+	s.Require().NoFileExists(s.path + ".2")
+}
+
+func (s *TestLogFileSuite) TestOpenRotates() {
+	w, err := Open(s.path, 3, 2)
+	s.Require().NoError(err)
+	s.Write(w, "aaaa")
+	s.Require().NoError(w.Close())
+	w, err = Open(s.path, 3, 2)
+	s.Require().NoError(err)
+	s.Write(w, "b")
+	s.Require().NoError(w.Close())
+	s.Require().Equal("b", s.Read(s.path))
+	s.Require().Equal("aaaa", s.Read(s.path+".1"))
+}
+
+func (s *TestLogFileSuite) TestConcurrentWrite() {
+	w, err := Open(s.path, 1000, 1)
+	s.Require().NoError(err)
+	var wg sync.WaitGroup
+	for idx := 0; idx < 10; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := 0; idx < 10; idx++ {
+				w.Write([]byte("0123456789"))
+			}
+		}()
+	}
+	wg.Wait()
+	s.Require().NoError(w.Close())
+	s.Require().Len(s.Read(s.path), 1000)
+}
+
+func (s *TestLogFileSuite) TestFilesNotExist() {
+	files, err := Files(s.path)
+	s.Require().NoError(err)
+	s.Require().Empty(files)
+}
+
+func (s *TestLogFileSuite) TestTail() {
+	s.Require().NoError(os.MkdirAll(path.Dir(s.path), 0755))
+	s.Require().NoError(ioutil.WriteFile(s.path, []byte("a\nb\nc\n"), 0644))
+	for n, expected := range map[int]string{0: "a\nb\nc\n", 2: "b\nc\n", 3: "a\nb\nc\n", 5: "a\nb\nc\n"} {
+		content, err := Tail(s.path, n)
+		s.Require().NoError(err)
+		s.Require().Equal(expected, string(content), n)
+	}
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (s *TestLogFileSuite) TestFollow() {
+	w, err := Open(s.path, 6, 2)
+	s.Require().NoError(err)
+	s.Write(w, "old\n")
+	var buf syncBuffer
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- Follow(s.path, 4, &buf, time.Millisecond, stop)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	s.Write(w, "a\n")
+	time.Sleep(20 * time.Millisecond)
+	s.Write(w, "b\n")
+	time.Sleep(20 * time.Millisecond)
+	s.Write(w, "c\n")
+	time.Sleep(20 * time.Millisecond)
+	close(stop)
+	s.Require().NoError(<-done)
+	s.Require().NoError(w.Close())
+	s.Require().Equal("a\nb\nc\n", buf.String())
+}
+
+func TestLogFileSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestLogFileSuite))
+}
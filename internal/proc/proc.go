@@ -0,0 +1,122 @@
+package proc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+var procDir = "/proc"
+
+// Cmdline returns command line arguments of the process
+func Cmdline(pid int) ([]string, error) {
+	bs, err := ioutil.ReadFile(path.Join(procDir, strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return nil, err
+	}
+	bs = bytes.TrimRight(bs, "\x00")
+	if len(bs) == 0 {
+		return nil, nil
+	}
+	parts := bytes.Split(bs, []byte{0})
+	args := make([]string, len(parts))
+	for idx, part := range parts {
+		args[idx] = string(part)
+	}
+	return args, nil
+}
+
+// FindByArgs returns PIDs of processes whose command line contains
+// the specified arguments in the specified order without gaps
+func FindByArgs(args ...string) ([]int, error) {
+	infos, err := ioutil.ReadDir(procDir)
+	if err != nil {
+		return nil, err
+	}
+	self := os.Getpid()
+	var pids []int
+	for _, info := range infos {
+		pid, err := strconv.Atoi(info.Name())
+		if err != nil || pid == self || !info.IsDir() {
+			continue
+		}
+		cmdline, err := Cmdline(pid)
+		if err != nil {
+			// the process has exited or is not accessible
+			continue
+		}
+		if containsArgs(cmdline, args) {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+func containsArgs(cmdline []string, args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	for start := 0; start+len(args) <= len(cmdline); start++ {
+		found := true
+		for idx, arg := range args {
+			if cmdline[start+idx] != arg {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// Alive checks whether the process exists and is not a zombie
+func Alive(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil && err != syscall.EPERM {
+		return false
+	}
+	bs, err := ioutil.ReadFile(path.Join(procDir, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		// the process may be hidden (e.g., /proc is mounted with hidepid)
+		return !os.IsNotExist(err)
+	}
+	// the state follows the command name enclosed in parentheses
+	idx := bytes.LastIndexByte(bs, ')')
+	if idx == -1 || idx+2 >= len(bs) {
+		return true
+	}
+	return bs[idx+2] != 'Z'
+}
+
+// Signal sends the signal to the processes, already exited processes are ignored
+func Signal(pids []int, sig syscall.Signal) error {
+	for _, pid := range pids {
+		if err := syscall.Kill(pid, sig); err != nil && err != syscall.ESRCH {
+			return err
+		}
+	}
+	return nil
+}
+
+// Wait waits until the processes exit and returns PIDs of processes still alive after the timeout
+func Wait(pids []int, timeout time.Duration) []int {
+	deadline := time.Now().Add(timeout)
+	for {
+		var alive []int
+		for _, pid := range pids {
+			if Alive(pid) {
+				alive = append(alive, pid)
+			}
+		}
+		if len(alive) == 0 || !time.Now().Before(deadline) {
+			return alive
+		}
+		pids = alive
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,120 @@
+package proc
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestProcSuite struct {
+	suite.Suite
+	dir         string
+	origProcDir string
+}
+
+func (s *TestProcSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "test-proc-*")
+	s.Require().NoError(err)
+	s.dir = dir
+	s.origProcDir = procDir
+	procDir = dir
+}
+
+func (s *TestProcSuite) TearDownTest() {
+	procDir = s.origProcDir
+	err := os.RemoveAll(s.dir)
+	s.Require().NoError(err)
+}
+
+func (s *TestProcSuite) CreateProcess(pid int, args ...string) {
+	pidDir := path.Join(s.dir, strconv.Itoa(pid))
+	err := os.Mkdir(pidDir, 0755)
+	s.Require().NoError(err)
+	cmdline := strings.Join(args, "\x00") + "\x00"
+	err = ioutil.WriteFile(path.Join(pidDir, "cmdline"), []byte(cmdline), 0644)
+	s.Require().NoError(err)
+}
+
+func (s *TestProcSuite) TestCmdline() {
+	s.CreateProcess(100, "telegram-desktop", "-many", "-workdir", "/path/to/profile")
+	args, err := Cmdline(100)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"telegram-desktop", "-many", "-workdir", "/path/to/profile"}, args)
+}
+
+func (s *TestProcSuite) TestCmdlineEmpty() {
+	s.CreateProcess(100)
+	args, err := Cmdline(100)
+	s.Require().NoError(err)
+	s.Require().Nil(args)
+}
+
+func (s *TestProcSuite) TestCmdlineNotExist() {
+	args, err := Cmdline(100)
+	s.Require().Error(err)
+	s.Require().True(os.IsNotExist(err), err)
+	s.Require().Nil(args)
+}
+
+func (s *TestProcSuite) TestFindByArgs() {
+	s.CreateProcess(100, "telegram-desktop", "-many", "-workdir", "/profiles/foo")
+	s.CreateProcess(101, "telegram-desktop", "-many", "-workdir", "/profiles/bar")
+	s.CreateProcess(102, "flatpak", "run", "org.telegram.desktop", "-many", "-workdir", "/profiles/foo")
+	s.CreateProcess(103, "editor", "/profiles/foo")
+	s.CreateProcess(104)
+	err := os.Mkdir(path.Join(s.dir, "self"), 0755)
+	s.Require().NoError(err)
+	pids, err := FindByArgs("-workdir", "/profiles/foo")
+	s.Require().NoError(err)
+	s.Require().Equal([]int{100, 102}, pids)
+}
+
+func (s *TestProcSuite) TestFindByArgsNotFound() {
+	s.CreateProcess(100, "telegram-desktop", "-many", "-workdir", "/profiles/foo")
+	pids, err := FindByArgs("-workdir", "/profiles/foobar")
+	s.Require().NoError(err)
+	s.Require().Nil(pids)
+}
+
+func TestProcSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestProcSuite))
+}
+
+type TestSignalSuite struct {
+	suite.Suite
+}
+
+func (s *TestSignalSuite) TestAlive() {
+	s.Require().True(Alive(os.Getpid()))
+	cmd := exec.Command("true")
+	s.Require().NoError(cmd.Start())
+	pid := cmd.Process.Pid
+	// the exited process is a zombie until it is waited for
+	s.Require().Empty(Wait([]int{pid}, 5*time.Second))
+	s.Require().False(Alive(pid))
+	s.Require().NoError(cmd.Wait())
+	s.Require().False(Alive(pid))
+}
+
+func (s *TestSignalSuite) TestSignalAndWait() {
+	cmd := exec.Command("sleep", "60")
+	s.Require().NoError(cmd.Start())
+	defer cmd.Wait()
+	pid := cmd.Process.Pid
+	s.Require().Equal([]int{pid}, Wait([]int{pid}, 100*time.Millisecond))
+	s.Require().NoError(Signal([]int{pid}, syscall.SIGTERM))
+	s.Require().Empty(Wait([]int{pid}, 5*time.Second))
+	s.Require().NoError(Signal([]int{pid}, syscall.SIGTERM))
+}
+
+func TestSignalSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestSignalSuite))
+}
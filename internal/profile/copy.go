@@ -0,0 +1,153 @@
+package profile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// session and key material of Telegram Desktop accounts (key_datas, hash-named account directories and files)
+var sessionRegexp = regexp.MustCompile(`^tdata/(key_data|[0-9A-F]{16})[s01]?(/|$)`)
+
+// media caches, logs and other data regenerated by Telegram Desktop
+var cacheRegexp = regexp.MustCompile(`^(tdata/(user_data(#\d+)?|emoji|dumps|temp|tdummy|working)|DebugLogs|log(_start\d*)?\.txt)(/|$)`)
+
+// IsSessionPath checks whether the path relative to the profile directory contains session/key material
+func IsSessionPath(relPath string) bool {
+	return sessionRegexp.MatchString(filepath.ToSlash(relPath))
+}
+
+// IsCachePath checks whether the path relative to the profile directory contains regenerable data
+func IsCachePath(relPath string) bool {
+	return cacheRegexp.MatchString(filepath.ToSlash(relPath))
+}
+
+// CopyOptions controls which data is copied by the Copy() function
+type CopyOptions struct {
+	SkipSession bool
+	SkipCache   bool
+}
+
+// Skip checks whether the path relative to the profile directory is excluded by the options
+func (o *CopyOptions) Skip(relPath string) bool {
+	if relPath == PidFileName {
+		return true
+	}
+	if o == nil {
+		return false
+	}
+	return (o.SkipSession && IsSessionPath(relPath)) || (o.SkipCache && IsCachePath(relPath))
+}
+
+// Copy copies the profile directory to a new profile preserving permissions
+func Copy(dir string, srcName string, dstName string, opts *CopyOptions) (*Profile, error) {
+	if !IsValidName(dstName) {
+		return nil, ErrInvalidName
+	}
+	src, err := Read(dir, srcName)
+	if err != nil {
+		return nil, err
+	}
+	dstPath := Path(dir, dstName)
+	if _, err := os.Lstat(dstPath); err == nil {
+		return nil, fmt.Errorf("%s: %w", dstPath, ErrAlreadyExists)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	running, err := src.IsRunning()
+	if err != nil {
+		return nil, err
+	}
+	if running {
+		return nil, fmt.Errorf("%s: %w", src.Path, ErrRunning)
+	}
+	if err := copyTree(src.Path, dstPath, opts.Skip); err != nil {
+		os.RemoveAll(dstPath)
+		return nil, err
+	}
+	return &Profile{dir, dstName, dstPath}, nil
+}
+
+type dirMode struct {
+	path string
+	info os.FileInfo
+}
+
+func copyTree(src string, dst string, skip func(string) bool) error {
+	// directory permissions are restored after copying to allow copying of read-only directories
+	var dirs []dirMode
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if relPath != "." && skip(relPath) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		dstPath := filepath.Join(dst, relPath)
+		mode := info.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.Mkdir(dstPath, 0700); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirMode{dstPath, info})
+			return nil
+		case mode&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(target, dstPath)
+		case mode.IsRegular():
+			return copyFile(path, dstPath, info)
+		default:
+			// sockets, pipes and devices are not copied
+			return nil
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for idx := len(dirs) - 1; idx >= 0; idx-- {
+		dir := dirs[idx]
+		if err := os.Chmod(dir.path, dir.info.Mode().Perm()); err != nil {
+			return err
+		}
+		if err := os.Chtimes(dir.path, dir.info.ModTime(), dir.info.ModTime()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src string, dst string, info os.FileInfo) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		dstFile.Close()
+		return err
+	}
+	if err := dstFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
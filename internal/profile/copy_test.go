@@ -0,0 +1,114 @@
+package profile
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestCopySuite struct {
+	BaseSuite
+	dstPath string
+}
+
+func (s *TestCopySuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	s.dstPath = path.Join(s.dir, "copy")
+}
+
+func (s *TestCopySuite) WriteFile(relPath string, perm os.FileMode) {
+	filePath := path.Join(s.path, relPath)
+	err := os.MkdirAll(path.Dir(filePath), 0755)
+	s.Require().NoError(err)
+	err = ioutil.WriteFile(filePath, []byte(relPath), perm)
+	s.Require().NoError(err)
+	err = os.Chmod(filePath, perm)
+	s.Require().NoError(err)
+}
+
+func (s *TestCopySuite) MakeProfile() {
+	s.MakeDir(true)
+	s.WriteFile("tdata/settingss", 0600)
+	s.WriteFile("tdata/key_datas", 0600)
+	s.WriteFile("tdata/D877F783D5D3EF8Cs", 0600)
+	s.WriteFile("tdata/D877F783D5D3EF8C/maps", 0600)
+	s.WriteFile("tdata/user_data/cache/0/data", 0644)
+	s.WriteFile("tdata/emoji/set_0", 0644)
+	s.WriteFile("log.txt", 0644)
+	s.WriteFile("script.sh", 0755)
+	err := os.Symlink("script.sh", path.Join(s.path, "link"))
+	s.Require().NoError(err)
+}
+
+func (s *TestCopySuite) TestOKFull() {
+	s.MakeProfile()
+	profile, err := Copy(s.dir, s.name, "copy", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(&Profile{s.dir, "copy", s.dstPath}, profile)
+	for _, relPath := range []string{
+		"tdata/settingss", "tdata/key_datas", "tdata/D877F783D5D3EF8Cs",
+		"tdata/D877F783D5D3EF8C/maps", "tdata/user_data/cache/0/data",
+		"tdata/emoji/set_0", "log.txt",
+	} {
+		content, err := ioutil.ReadFile(path.Join(s.dstPath, relPath))
+		s.Require().NoError(err)
+		s.Require().Equal(relPath, string(content))
+	}
+	info, err := os.Stat(path.Join(s.dstPath, "tdata/key_datas"))
+	s.Require().NoError(err)
+	s.Require().Equal(os.FileMode(0600), info.Mode().Perm())
+	info, err = os.Stat(path.Join(s.dstPath, "script.sh"))
+	s.Require().NoError(err)
+	s.Require().Equal(os.FileMode(0755), info.Mode().Perm())
+	target, err := os.Readlink(path.Join(s.dstPath, "link"))
+	s.Require().NoError(err)
+	s.Require().Equal("script.sh", target)
+}
+
+func (s *TestCopySuite) TestOKSkipSessionAndCache() {
+	s.MakeProfile()
+	_, err := Copy(s.dir, s.name, "copy", &CopyOptions{SkipSession: true, SkipCache: true})
+	s.Require().NoError(err)
+	s.Require().FileExists(path.Join(s.dstPath, "tdata/settingss"))
+	s.Require().FileExists(path.Join(s.dstPath, "script.sh"))
+	for _, relPath := range []string{
+		"tdata/key_datas", "tdata/D877F783D5D3EF8Cs", "tdata/D877F783D5D3EF8C",
+		"tdata/user_data", "tdata/emoji", "log.txt",
+	} {
+		_, err := os.Lstat(path.Join(s.dstPath, relPath))
+		s.Require().True(os.IsNotExist(err), relPath)
+	}
+}
+
+func (s *TestCopySuite) TestErrorNotExist() {
+	profile, err := Copy(s.dir, s.name, "copy", nil)
+	s.Require().Error(err)
+	s.Require().True(errors.Is(err, ErrNotExist), err)
+	s.Require().Nil(profile)
+}
+
+func (s *TestCopySuite) TestErrorAlreadyExists() {
+	s.MakeDir(false)
+	err := os.Mkdir(s.dstPath, 0755)
+	s.Require().NoError(err)
+	profile, err := Copy(s.dir, s.name, "copy", nil)
+	s.Require().Error(err)
+	s.Require().True(errors.Is(err, ErrAlreadyExists), err)
+	s.Require().Nil(profile)
+}
+
+func (s *TestCopySuite) TestErrorInvalidName() {
+	s.MakeDir(false)
+	profile, err := Copy(s.dir, s.name, "../copy", nil)
+	s.Require().Error(err)
+	s.Require().Regexp("invalid profile name", err.Error())
+	s.Require().Nil(profile)
+}
+
+func TestCopySuiteTest(t *testing.T) {
+	suite.Run(t, new(TestCopySuite))
+}
@@ -3,10 +3,14 @@ package profile
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/un-def/manygram/internal/trash"
 )
 
 // Profile type
@@ -22,6 +26,9 @@ var ErrAlreadyExists = errors.New("already exists")
 // ErrNotExist is returned by the Read() and Remove() functions when the profile directory does not exist
 var ErrNotExist = os.ErrNotExist
 
+// ErrRunning is returned when the operation cannot be performed on the running profile
+var ErrRunning = errors.New("profile is running")
+
 // ErrInvalidName indicates that the profile name does not meet requirements
 var ErrInvalidName = errors.New("invalid profile name")
 
@@ -53,6 +60,20 @@ func Create(dir string, name string) (*Profile, error) {
 	return nil, fmt.Errorf("%s: %w", path, ErrAlreadyExists)
 }
 
+// CheckNew checks that the profile with the name can be created
+func CheckNew(dir string, name string) error {
+	if !IsValidName(name) {
+		return ErrInvalidName
+	}
+	path := Path(dir, name)
+	if _, err := os.Lstat(path); err == nil {
+		return fmt.Errorf("%s: %w", path, ErrAlreadyExists)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 // Read checks if the profile directory exists
 func Read(dir string, name string) (*Profile, error) {
 	if !IsValidName(name) {
@@ -69,16 +90,138 @@ func Read(dir string, name string) (*Profile, error) {
 	return &Profile{dir, name, path}, nil
 }
 
-// Remove removes the profile directory
-func Remove(dir string, name string) error {
+// Remove removes the profile directory, the running profile is removed only if force is true
+func Remove(dir string, name string, force bool) error {
+	prof, err := readStopped(dir, name, force)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(prof.Path)
+}
+
+// Trash moves the profile directory to the trash, the running profile is moved only if force is true
+func Trash(dir string, name string, force bool, trashDir string) error {
+	prof, err := readStopped(dir, name, force)
+	if err != nil {
+		return err
+	}
+	_, err = trash.Put(trashDir, prof.Path)
+	return err
+}
+
+// Restore moves the most recently trashed profile directory back to the profile directory
+func Restore(dir string, name string, trashDir string) (*Profile, error) {
 	if !IsValidName(name) {
-		return ErrInvalidName
+		return nil, ErrInvalidName
 	}
 	path := Path(dir, name)
-	if _, err := os.Stat(path); err != nil {
-		return err
+	if _, err := os.Lstat(path); err == nil {
+		return nil, fmt.Errorf("%s: %w", path, ErrAlreadyExists)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	item, err := trash.Find(trashDir, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := trash.Restore(trashDir, item); err != nil {
+		return nil, err
+	}
+	return &Profile{dir, name, path}, nil
+}
+
+// readStopped reads the profile and checks that it is not running unless force is true
+func readStopped(dir string, name string, force bool) (*Profile, error) {
+	prof, err := Read(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	if !force {
+		running, err := prof.IsRunning()
+		if err != nil {
+			return nil, err
+		}
+		if running {
+			return nil, fmt.Errorf("%s: %w", prof.Path, ErrRunning)
+		}
+	}
+	return prof, nil
+}
+
+// List returns profiles found in the profile directory sorted by name
+func List(dir string) ([]*Profile, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var profiles []*Profile
+	for _, info := range infos {
+		name := info.Name()
+		if !IsValidName(name) {
+			continue
+		}
+		path := Path(dir, name)
+		if info.Mode()&os.ModeSymlink != 0 {
+			if info, err = os.Stat(path); err != nil {
+				continue
+			}
+		}
+		if !info.IsDir() {
+			continue
+		}
+		profiles = append(profiles, &Profile{dir, name, path})
+	}
+	return profiles, nil
+}
+
+// Size returns the total size of regular files in the profile directory,
+// files removed by the running Telegram Desktop during the walk are skipped
+func (p *Profile) Size() (int64, error) {
+	var size int64
+	err := filepath.Walk(p.Path, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil && os.IsNotExist(err) && filePath != p.Path {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// Rename renames the profile directory
+func Rename(dir string, oldName string, newName string) (*Profile, error) {
+	if !IsValidName(newName) {
+		return nil, ErrInvalidName
+	}
+	prof, err := Read(dir, oldName)
+	if err != nil {
+		return nil, err
+	}
+	newPath := Path(dir, newName)
+	if _, err := os.Lstat(newPath); err == nil {
+		return nil, fmt.Errorf("%s: %w", newPath, ErrAlreadyExists)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	running, err := prof.IsRunning()
+	if err != nil {
+		return nil, err
+	}
+	if running {
+		return nil, fmt.Errorf("%s: %w", prof.Path, ErrRunning)
+	}
+	if err := os.Rename(prof.Path, newPath); err != nil {
+		return nil, err
 	}
-	return os.RemoveAll(path)
+	return &Profile{dir, newName, newPath}, nil
 }
 
 // IsValidName checks whether the profile name meets requirements
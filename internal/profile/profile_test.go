@@ -5,9 +5,12 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
+
+	"github.com/un-def/manygram/internal/trash"
 )
 
 type BaseSuite struct {
@@ -91,6 +94,31 @@ func TestCreateSuiteTest(t *testing.T) {
 	suite.Run(t, new(TestCreateSuite))
 }
 
+// CheckNew tests
+
+type TestCheckNewSuite struct {
+	BaseSuite
+}
+
+func (s *TestCheckNewSuite) TestOK() {
+	s.Require().NoError(CheckNew(s.dir, s.name))
+}
+
+func (s *TestCheckNewSuite) TestErrorExist() {
+	s.MakeDir(true)
+	err := CheckNew(s.dir, s.name)
+	s.Require().True(errors.Is(err, ErrAlreadyExists), err)
+}
+
+func (s *TestCheckNewSuite) TestErrorInvalidName() {
+	err := CheckNew(s.dir, "1abc")
+	s.Require().True(errors.Is(err, ErrInvalidName), err)
+}
+
+func TestCheckNewSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestCheckNewSuite))
+}
+
 // Read tests
 
 type TestReadSuite struct {
@@ -146,14 +174,14 @@ type TestRemoveSuite struct {
 }
 
 func (s *TestRemoveSuite) TestErrorNotExist() {
-	err := Remove(s.dir, "non_existent")
+	err := Remove(s.dir, "non_existent", false)
 	s.Require().Error(err)
 	s.Require().True(errors.Is(err, ErrNotExist), err)
 }
 
 func (s *TestRemoveSuite) TestOK() {
 	s.MakeDir(false)
-	err := Remove(s.dir, s.name)
+	err := Remove(s.dir, s.name, false)
 	s.Require().NoError(err)
 	_, err = os.Stat(s.path)
 	s.Require().Error(err)
@@ -161,7 +189,7 @@ func (s *TestRemoveSuite) TestOK() {
 }
 
 func (s *TestRemoveSuite) TestErrorInvalidName() {
-	err := Remove(s.dir, "")
+	err := Remove(s.dir, "", false)
 	s.Require().Error(err)
 	s.Require().Regexp("invalid profile name", err.Error())
 }
@@ -170,6 +198,185 @@ func TestRemoveSuiteTest(t *testing.T) {
 	suite.Run(t, new(TestRemoveSuite))
 }
 
+// Trash tests
+
+type TestTrashSuite struct {
+	BaseSuite
+}
+
+func (s *TestTrashSuite) TestOK() {
+	s.MakeDir(false)
+	trashDir := path.Join(s.dir, "Trash")
+	err := Trash(s.dir, s.name, false, trashDir)
+	s.Require().NoError(err)
+	s.Require().NoDirExists(s.path)
+	s.Require().FileExists(path.Join(trashDir, "files", s.name, "some-file"))
+	profile, err := Restore(s.dir, s.name, trashDir)
+	s.Require().NoError(err)
+	s.Require().Equal(&Profile{s.dir, s.name, s.path}, profile)
+	s.Require().FileExists(path.Join(s.path, "some-file"))
+}
+
+func (s *TestTrashSuite) TestErrorNotExist() {
+	err := Trash(s.dir, "non_existent", false, path.Join(s.dir, "Trash"))
+	s.Require().True(errors.Is(err, ErrNotExist), err)
+}
+
+func (s *TestTrashSuite) TestRestoreErrorNotInTrash() {
+	_, err := Restore(s.dir, s.name, path.Join(s.dir, "Trash"))
+	s.Require().True(errors.Is(err, trash.ErrNotInTrash), err)
+}
+
+func (s *TestTrashSuite) TestRestoreErrorAlreadyExists() {
+	s.MakeDir(true)
+	_, err := Restore(s.dir, s.name, path.Join(s.dir, "Trash"))
+	s.Require().True(errors.Is(err, ErrAlreadyExists), err)
+}
+
+func TestTrashSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestTrashSuite))
+}
+
+// Rename tests
+
+type TestRenameSuite struct {
+	BaseSuite
+}
+
+func (s *TestRenameSuite) TestOK() {
+	s.MakeDir(false)
+	profile, err := Rename(s.dir, s.name, "newname")
+	s.Require().NoError(err)
+	newPath := path.Join(s.dir, "newname")
+	s.Require().Equal(&Profile{s.dir, "newname", newPath}, profile)
+	s.Require().NoDirExists(s.path)
+	s.Require().FileExists(path.Join(newPath, "some-file"))
+}
+
+func (s *TestRenameSuite) TestErrorNotExist() {
+	profile, err := Rename(s.dir, s.name, "newname")
+	s.Require().Error(err)
+	s.Require().True(errors.Is(err, ErrNotExist), err)
+	s.Require().Nil(profile)
+}
+
+func (s *TestRenameSuite) TestErrorAlreadyExists() {
+	s.MakeDir(false)
+	err := os.Mkdir(path.Join(s.dir, "newname"), 0755)
+	s.Require().NoError(err)
+	profile, err := Rename(s.dir, s.name, "newname")
+	s.Require().Error(err)
+	s.Require().True(errors.Is(err, ErrAlreadyExists), err)
+	s.Require().Nil(profile)
+	s.Require().DirExists(s.path)
+}
+
+func (s *TestRenameSuite) TestErrorInvalidName() {
+	s.MakeDir(false)
+	profile, err := Rename(s.dir, s.name, "new/name")
+	s.Require().Error(err)
+	s.Require().Regexp("invalid profile name", err.Error())
+	s.Require().Nil(profile)
+	profile, err = Rename(s.dir, "1name", "newname")
+	s.Require().Error(err)
+	s.Require().Regexp("invalid profile name", err.Error())
+	s.Require().Nil(profile)
+}
+
+func TestRenameSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestRenameSuite))
+}
+
+// List tests
+
+type TestListSuite struct {
+	BaseSuite
+}
+
+func (s *TestListSuite) TestOK() {
+	for _, name := range []string{"foo", "bar", "1invalid", ".hidden"} {
+		err := os.Mkdir(path.Join(s.dir, name), 0755)
+		s.Require().NoError(err)
+	}
+	f, err := os.Create(path.Join(s.dir, "file"))
+	s.Require().NoError(err)
+	f.Close()
+	err = os.Symlink(path.Join(s.dir, "foo"), path.Join(s.dir, "link"))
+	s.Require().NoError(err)
+	profiles, err := List(s.dir)
+	s.Require().NoError(err)
+	s.Require().Equal([]*Profile{
+		{s.dir, "bar", path.Join(s.dir, "bar")},
+		{s.dir, "foo", path.Join(s.dir, "foo")},
+		{s.dir, "link", path.Join(s.dir, "link")},
+	}, profiles)
+}
+
+func (s *TestListSuite) TestOKEmpty() {
+	profiles, err := List(s.dir)
+	s.Require().NoError(err)
+	s.Require().Empty(profiles)
+}
+
+func (s *TestListSuite) TestErrorNotExist() {
+	profiles, err := List(path.Join(s.dir, "profiles"))
+	s.Require().Error(err)
+	s.Require().True(errors.Is(err, ErrNotExist), err)
+	s.Require().Nil(profiles)
+}
+
+func TestListSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestListSuite))
+}
+
+// Size tests
+
+type TestSizeSuite struct {
+	BaseSuite
+}
+
+func (s *TestSizeSuite) TestOK() {
+	s.MakeDir(true)
+	err := os.Mkdir(path.Join(s.path, "tdata"), 0755)
+	s.Require().NoError(err)
+	err = ioutil.WriteFile(path.Join(s.path, "tdata", "settings"), make([]byte, 100), 0644)
+	s.Require().NoError(err)
+	err = ioutil.WriteFile(path.Join(s.path, "log.txt"), make([]byte, 20), 0644)
+	s.Require().NoError(err)
+	size, err := (&Profile{s.dir, s.name, s.path}).Size()
+	s.Require().NoError(err)
+	s.Require().Equal(int64(120), size)
+}
+
+func (s *TestSizeSuite) TestFilesRemovedDuringWalk() {
+	s.MakeDir(true)
+	cache := path.Join(s.path, "tdata", "user_data", "cache")
+	err := os.MkdirAll(cache, 0755)
+	s.Require().NoError(err)
+	for idx := 0; idx < 2000; idx++ {
+		err = ioutil.WriteFile(path.Join(cache, strconv.Itoa(idx)), nil, 0644)
+		s.Require().NoError(err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- os.RemoveAll(cache)
+	}()
+	for {
+		_, err = (&Profile{s.dir, s.name, s.path}).Size()
+		s.Require().NoError(err)
+		select {
+		case err = <-done:
+			s.Require().NoError(err)
+			return
+		default:
+		}
+	}
+}
+
+func TestSizeSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestSizeSuite))
+}
+
 // IsProfileDirExist tests
 
 type TestIsProfileDirExistSuite struct {
@@ -0,0 +1,145 @@
+package profile
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/un-def/manygram/internal/proc"
+)
+
+// PidFileName is the name of the file in the profile directory
+// containing PID of Telegram Desktop process started by manygram
+const PidFileName = "manygram.pid"
+
+// killTimeout is the time given to the killed processes to disappear
+const killTimeout = 5 * time.Second
+
+func (p *Profile) pidFilePath() string {
+	return path.Join(p.Path, PidFileName)
+}
+
+// WritePid writes PID of the started Telegram Desktop process into the pid file
+func (p *Profile) WritePid(pid int) error {
+	return ioutil.WriteFile(p.pidFilePath(), []byte(strconv.Itoa(pid)+"\n"), 0644)
+}
+
+// RemovePid removes the pid file
+func (p *Profile) RemovePid() error {
+	err := os.Remove(p.pidFilePath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// readPid returns PID from the pid file if the process is still alive and uses the profile
+func (p *Profile) readPid() (int, error) {
+	bs, err := ioutil.ReadFile(p.pidFilePath())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(bs)))
+	if err != nil || pid <= 0 {
+		// malformed pid file is considered stale
+		return 0, nil
+	}
+	if !proc.Alive(pid) {
+		return 0, nil
+	}
+	cmdline, err := proc.Cmdline(pid)
+	if err != nil {
+		// the process is alive but its command line is not accessible (e.g., /proc is mounted with hidepid)
+		return pid, nil
+	}
+	for _, arg := range cmdline {
+		if arg == p.Path {
+			return pid, nil
+		}
+	}
+	// PID has been reused by an unrelated process
+	return 0, nil
+}
+
+// Pids returns PIDs of Telegram Desktop processes using the profile directory
+// found by -workdir argument or by the pid file
+func (p *Profile) Pids() ([]int, error) {
+	pids, err := proc.FindByArgs("-workdir", p.Path)
+	if err != nil {
+		return nil, err
+	}
+	pid, err := p.readPid()
+	if err != nil {
+		return nil, err
+	}
+	if pid == 0 {
+		return pids, nil
+	}
+	for _, found := range pids {
+		if found == pid {
+			return pids, nil
+		}
+	}
+	return append(pids, pid), nil
+}
+
+// IsRunning checks whether the profile is used by a Telegram Desktop process
+func (p *Profile) IsRunning() (bool, error) {
+	pids, err := p.Pids()
+	if err != nil {
+		return false, err
+	}
+	return len(pids) > 0, nil
+}
+
+// Stop sends SIGTERM to Telegram Desktop processes using the profile and waits
+// until they exit, SIGKILL is sent to the remaining processes if kill is true;
+// it returns PIDs of the signaled processes and PIDs of the processes still alive
+func (p *Profile) Stop(timeout time.Duration, kill bool) ([]int, []int, error) {
+	pids, err := p.Pids()
+	if err != nil || len(pids) == 0 {
+		return nil, nil, err
+	}
+	if err := proc.Signal(pids, syscall.SIGTERM); err != nil {
+		return nil, nil, err
+	}
+	alive := proc.Wait(pids, timeout)
+	if len(alive) > 0 && kill {
+		if err := proc.Signal(alive, syscall.SIGKILL); err != nil {
+			return nil, nil, err
+		}
+		alive = proc.Wait(alive, killTimeout)
+	}
+	if len(alive) == 0 {
+		if err := p.RemovePid(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return pids, alive, nil
+}
+
+// Kill sends SIGKILL to Telegram Desktop processes using the profile;
+// it returns PIDs of the signaled processes and PIDs of the processes still alive
+func (p *Profile) Kill() ([]int, []int, error) {
+	pids, err := p.Pids()
+	if err != nil || len(pids) == 0 {
+		return nil, nil, err
+	}
+	if err := proc.Signal(pids, syscall.SIGKILL); err != nil {
+		return nil, nil, err
+	}
+	alive := proc.Wait(pids, killTimeout)
+	if len(alive) == 0 {
+		if err := p.RemovePid(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return pids, alive, nil
+}
@@ -0,0 +1,179 @@
+package profile
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/un-def/manygram/internal/proc"
+)
+
+type TestRunningSuite struct {
+	BaseSuite
+	profile *Profile
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+}
+
+func (s *TestRunningSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	s.MakeDir(false)
+	s.profile = &Profile{s.dir, s.name, s.path}
+}
+
+func (s *TestRunningSuite) TearDownTest() {
+	if s.cmd != nil {
+		s.cmd.Process.Kill()
+		s.cmd.Wait()
+		s.cmd = nil
+		s.stdin.Close()
+	}
+	s.BaseSuite.TearDownTest()
+}
+
+// StartProcess starts a fake long-running process with the specified extra arguments
+func (s *TestRunningSuite) StartProcess(args ...string) int {
+	return s.StartScript("read line", args...)
+}
+
+// StartScript starts a shell script with the specified extra arguments, the script should block
+// with builtins reading the stdin, a forked child has the same arguments until it execs a command
+func (s *TestRunningSuite) StartScript(script string, args ...string) int {
+	s.cmd = exec.Command("sh", append([]string{"-c", script, "sh"}, args...)...)
+	stdin, err := s.cmd.StdinPipe()
+	s.Require().NoError(err)
+	s.stdin = stdin
+	s.Require().NoError(s.cmd.Start())
+	pid := s.cmd.Process.Pid
+	// the command line is empty until the kernel finishes exec
+	s.Require().Eventually(func() bool {
+		cmdline, err := proc.Cmdline(pid)
+		return err == nil && len(cmdline) > 0
+	}, time.Second, time.Millisecond)
+	return pid
+}
+
+func (s *TestRunningSuite) TestNotRunning() {
+	running, err := s.profile.IsRunning()
+	s.Require().NoError(err)
+	s.Require().False(running)
+}
+
+func (s *TestRunningSuite) TestRunningWorkdir() {
+	pid := s.StartProcess("-many", "-workdir", s.path)
+	pids, err := s.profile.Pids()
+	s.Require().NoError(err)
+	s.Require().Equal([]int{pid}, pids)
+	running, err := s.profile.IsRunning()
+	s.Require().NoError(err)
+	s.Require().True(running)
+}
+
+func (s *TestRunningSuite) TestRunningPidFile() {
+	pid := s.StartProcess(s.path)
+	s.Require().NoError(s.profile.WritePid(pid))
+	pids, err := s.profile.Pids()
+	s.Require().NoError(err)
+	s.Require().Equal([]int{pid}, pids)
+}
+
+func (s *TestRunningSuite) TestStalePidFile() {
+	cmd := exec.Command("true")
+	s.Require().NoError(cmd.Run())
+	s.Require().NoError(s.profile.WritePid(cmd.Process.Pid))
+	running, err := s.profile.IsRunning()
+	s.Require().NoError(err)
+	s.Require().False(running)
+}
+
+func (s *TestRunningSuite) TestReusedPidFile() {
+	pid := s.StartProcess()
+	s.Require().NoError(s.profile.WritePid(pid))
+	running, err := s.profile.IsRunning()
+	s.Require().NoError(err)
+	s.Require().False(running)
+}
+
+func (s *TestRunningSuite) TestRemovePid() {
+	s.Require().NoError(s.profile.WritePid(123))
+	content, err := ioutil.ReadFile(path.Join(s.path, PidFileName))
+	s.Require().NoError(err)
+	s.Require().Equal(strconv.Itoa(123)+"\n", string(content))
+	s.Require().NoError(s.profile.RemovePid())
+	s.Require().NoFileExists(path.Join(s.path, PidFileName))
+	s.Require().NoError(s.profile.RemovePid())
+}
+
+func (s *TestRunningSuite) TestRemoveRunning() {
+	s.StartProcess("-workdir", s.path)
+	err := Remove(s.dir, s.name, false)
+	s.Require().True(errors.Is(err, ErrRunning), err)
+	s.Require().DirExists(s.path)
+	err = Remove(s.dir, s.name, true)
+	s.Require().NoError(err)
+	s.Require().NoDirExists(s.path)
+}
+
+func (s *TestRunningSuite) TestRenameRunning() {
+	s.StartProcess("-workdir", s.path)
+	profile, err := Rename(s.dir, s.name, "newname")
+	s.Require().True(errors.Is(err, ErrRunning), err)
+	s.Require().Nil(profile)
+}
+
+func (s *TestRunningSuite) TestCopyRunning() {
+	s.StartProcess("-workdir", s.path)
+	profile, err := Copy(s.dir, s.name, "newname", nil)
+	s.Require().True(errors.Is(err, ErrRunning), err)
+	s.Require().Nil(profile)
+}
+
+func (s *TestRunningSuite) TestStop() {
+	pid := s.StartProcess("-workdir", s.path)
+	s.Require().NoError(s.profile.WritePid(pid))
+	pids, alive, err := s.profile.Stop(5*time.Second, false)
+	s.Require().NoError(err)
+	s.Require().Equal([]int{pid}, pids)
+	s.Require().Empty(alive)
+	s.Require().NoFileExists(path.Join(s.path, PidFileName))
+}
+
+func (s *TestRunningSuite) TestStopNotRunning() {
+	pids, alive, err := s.profile.Stop(time.Second, false)
+	s.Require().NoError(err)
+	s.Require().Empty(pids)
+	s.Require().Empty(alive)
+}
+
+func (s *TestRunningSuite) TestStopIgnoringSIGTERM() {
+	pid := s.StartScript("trap '' TERM; read line", "-workdir", s.path)
+	// give the shell time to set up the trap
+	time.Sleep(200 * time.Millisecond)
+	pids, alive, err := s.profile.Stop(300*time.Millisecond, false)
+	s.Require().NoError(err)
+	s.Require().Equal([]int{pid}, pids)
+	s.Require().Equal([]int{pid}, alive)
+	pids, alive, err = s.profile.Stop(300*time.Millisecond, true)
+	s.Require().NoError(err)
+	s.Require().Equal([]int{pid}, pids)
+	s.Require().Empty(alive)
+}
+
+func (s *TestRunningSuite) TestKill() {
+	pid := s.StartProcess("-workdir", s.path)
+	pids, alive, err := s.profile.Kill()
+	s.Require().NoError(err)
+	s.Require().Equal([]int{pid}, pids)
+	s.Require().Empty(alive)
+}
+
+func TestRunningSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestRunningSuite))
+}
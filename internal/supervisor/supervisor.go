@@ -0,0 +1,125 @@
+package supervisor
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// ErrTooManyCrashes is returned by the Run() method when the process crashed MaxCrashes times in a row
+var ErrTooManyCrashes = errors.New("too many crashes")
+
+// Default values of the Supervisor fields
+const (
+	DefaultMaxCrashes = 5
+	DefaultMinDelay   = time.Second
+	DefaultMaxDelay   = 5 * time.Minute
+	DefaultResetAfter = 10 * time.Minute
+)
+
+// Supervisor restarts the process when it exits with non-zero status or is killed by a signal,
+// the process terminated with SIGTERM, SIGINT or SIGKILL (e.g. by `manygram stop`) is not restarted
+type Supervisor struct {
+	// Start starts the process, it is called for every restart
+	Start func() (*exec.Cmd, error)
+	// MaxCrashes is the number of crashes in a row after which the supervisor gives up, 0 means no limit
+	MaxCrashes int
+	// MinDelay is the delay before the first restart, it is doubled after every crash up to MaxDelay
+	MinDelay time.Duration
+	MaxDelay time.Duration
+	// ResetAfter is the run time after which the process is considered healthy
+	// and the crash counter and the delay are reset
+	ResetAfter time.Duration
+	// Logf is called to report crashes and restarts, can be nil
+	Logf func(format string, args ...interface{})
+	// Crashes is the total number of crashes
+	Crashes int
+}
+
+// New returns a supervisor with default settings
+func New(start func() (*exec.Cmd, error)) *Supervisor {
+	return &Supervisor{
+		Start:      start,
+		MaxCrashes: DefaultMaxCrashes,
+		MinDelay:   DefaultMinDelay,
+		MaxDelay:   DefaultMaxDelay,
+		ResetAfter: DefaultResetAfter,
+	}
+}
+
+func (s *Supervisor) logf(format string, args ...interface{}) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
+	}
+}
+
+// Run starts the process and restarts it after crashes until it exits successfully,
+// crashes MaxCrashes times in a row or the stop channel is closed,
+// in the latter case the process is terminated with SIGTERM
+func (s *Supervisor) Run(stop <-chan struct{}) error {
+	delay := s.MinDelay
+	crashes := 0
+	for {
+		started := time.Now()
+		cmd, err := s.Start()
+		if err != nil {
+			return err
+		}
+		done := make(chan error, 1)
+		go func() {
+			done <- cmd.Wait()
+		}()
+		select {
+		case err = <-done:
+		case <-stop:
+			cmd.Process.Signal(syscall.SIGTERM)
+			<-done
+			return nil
+		}
+		if err == nil {
+			return nil
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return err
+		}
+		if isStopSignal(exitErr) {
+			s.logf("stopped (%s)", exitErr.ProcessState)
+			return nil
+		}
+		if time.Since(started) >= s.ResetAfter {
+			crashes = 0
+			delay = s.MinDelay
+		}
+		crashes++
+		s.Crashes++
+		s.logf("crashed (%s), %d in a row, %d in total", exitErr.ProcessState, crashes, s.Crashes)
+		if s.MaxCrashes > 0 && crashes >= s.MaxCrashes {
+			return ErrTooManyCrashes
+		}
+		s.logf("restarting in %s", delay)
+		select {
+		case <-time.After(delay):
+		case <-stop:
+			return nil
+		}
+		delay *= 2
+		if delay > s.MaxDelay {
+			delay = s.MaxDelay
+		}
+	}
+}
+
+// isStopSignal checks whether the process has been terminated with a signal sent to stop it
+func isStopSignal(exitErr *exec.ExitError) bool {
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return false
+	}
+	switch status.Signal() {
+	case syscall.SIGTERM, syscall.SIGINT, syscall.SIGKILL:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package supervisor
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSupervisorSuite struct {
+	suite.Suite
+	dir    string
+	starts int
+	logs   []string
+}
+
+func (s *TestSupervisorSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "test-supervisor-*")
+	s.Require().NoError(err)
+	s.dir = dir
+	s.starts = 0
+	s.logs = nil
+}
+
+func (s *TestSupervisorSuite) TearDownTest() {
+	err := os.RemoveAll(s.dir)
+	s.Require().NoError(err)
+}
+
+// New returns a supervisor running the script, the script gets the start number as $1
+func (s *TestSupervisorSuite) New(script string) *Supervisor {
+	sup := New(func() (*exec.Cmd, error) {
+		s.starts++
+		cmd := exec.Command("sh", "-c", script, "sh", fmt.Sprint(s.starts))
+		return cmd, cmd.Start()
+	})
+	sup.MinDelay = time.Millisecond
+	sup.MaxDelay = 4 * time.Millisecond
+	sup.Logf = func(format string, args ...interface{}) {
+		s.logs = append(s.logs, fmt.Sprintf(format, args...))
+	}
+	return sup
+}
+
+func (s *TestSupervisorSuite) TestExitOK() {
+	err := s.New("exit 0").Run(nil)
+	s.Require().NoError(err)
+	s.Require().Equal(1, s.starts)
+	s.Require().Empty(s.logs)
+}
+
+func (s *TestSupervisorSuite) TestRestart() {
+	sup := s.New(`[ "$1" -ge 3 ] || exit 1`)
+	err := sup.Run(nil)
+	s.Require().NoError(err)
+	s.Require().Equal(3, s.starts)
+	s.Require().Equal(2, sup.Crashes)
+	s.Require().Contains(s.logs[0], "crashed (exit status 1), 1 in a row, 1 in total")
+	s.Require().Equal("restarting in 1ms", s.logs[1])
+	s.Require().Equal("restarting in 2ms", s.logs[3])
+}
+
+func (s *TestSupervisorSuite) TestSignal() {
+	sup := s.New(`[ "$1" -ge 2 ] || kill -SEGV $$`)
+	err := sup.Run(nil)
+	s.Require().NoError(err)
+	s.Require().Equal(2, s.starts)
+	s.Require().Contains(s.logs[0], "segmentation fault")
+}
+
+func (s *TestSupervisorSuite) TestStopSignal() {
+	for _, signal := range []string{"TERM", "INT", "KILL"} {
+		s.starts, s.logs = 0, nil
+		sup := s.New("kill -" + signal + " $$")
+		err := sup.Run(nil)
+		s.Require().NoError(err, signal)
+		s.Require().Equal(1, s.starts, signal)
+		s.Require().Equal(0, sup.Crashes, signal)
+		s.Require().Len(s.logs, 1, signal)
+		s.Require().Contains(s.logs[0], "stopped (signal: ", signal)
+	}
+}
+
+func (s *TestSupervisorSuite) TestTooManyCrashes() {
+	sup := s.New("exit 3")
+	sup.MaxCrashes = 4
+	err := sup.Run(nil)
+	s.Require().True(errors.Is(err, ErrTooManyCrashes), err)
+	s.Require().Equal(4, s.starts)
+	s.Require().Equal("restarting in 4ms", s.logs[len(s.logs)-2])
+}
+
+func (s *TestSupervisorSuite) TestResetAfter() {
+	sup := s.New(`[ "$1" -ge 4 ] || exit 1`)
+	sup.MaxCrashes = 2
+	sup.ResetAfter = 0
+	err := sup.Run(nil)
+	s.Require().NoError(err)
+	s.Require().Equal(3, sup.Crashes)
+	for _, log := range s.logs {
+		s.Require().False(strings.Contains(log, "crash 2"), log)
+	}
+}
+
+func (s *TestSupervisorSuite) TestStop() {
+	marker := path.Join(s.dir, "terminated")
+	sup := s.New(fmt.Sprintf(`trap 'touch %s; exit 0' TERM; while true; do sleep 0.01; done`, marker))
+	stop := make(chan struct{})
+	time.AfterFunc(200*time.Millisecond, func() { close(stop) })
+	err := sup.Run(stop)
+	s.Require().NoError(err)
+	s.Require().FileExists(marker)
+}
+
+func (s *TestSupervisorSuite) TestStartError() {
+	sup := New(func() (*exec.Cmd, error) {
+		return nil, os.ErrNotExist
+	})
+	err := sup.Run(nil)
+	s.Require().True(errors.Is(err, os.ErrNotExist), err)
+}
+
+func TestSupervisorSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestSupervisorSuite))
+}
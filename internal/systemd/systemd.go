@@ -0,0 +1,120 @@
+package systemd
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/un-def/manygram/internal/util"
+)
+
+var unitTemplate = template.Must(template.New("Unit").Parse(`[Unit]
+Description={{.Description}}
+PartOf=graphical-session.target
+After=graphical-session.target
+
+[Service]
+Type=simple
+ExecStart={{.ExecStart}}
+Restart=on-failure
+RestartSec=5
+
+[Install]
+WantedBy=graphical-session.target
+`))
+
+// TemplateName is the file name of the template unit, the instance name is the profile name
+const TemplateName = "manygram@.service"
+
+const unitPrefix = "manygram-"
+const unitSuffix = ".service"
+
+type unitValues struct {
+	Description string
+	ExecStart   string
+}
+
+// InstanceName returns the name of the template unit instance running the profile
+func InstanceName(profile string) string {
+	return "manygram@" + profile + unitSuffix
+}
+
+// UnitName returns the name of the unit running the profile
+func UnitName(profile string) string {
+	return unitPrefix + profile + unitSuffix
+}
+
+// TemplatePath builds a path to the template unit
+func TemplatePath(dir string) string {
+	return path.Join(dir, TemplateName)
+}
+
+// UnitPath builds a path to the unit running the profile
+func UnitPath(dir, profile string) string {
+	return path.Join(dir, UnitName(profile))
+}
+
+// CreateTemplate creates or rewrites the template unit running `manygram run --wait %i`
+func CreateTemplate(dir string, execPath string) error {
+	return write(TemplatePath(dir), &unitValues{
+		Description: "Telegram Desktop (manygram profile %i)",
+		ExecStart:   ExecLine(execPath, "run", "--wait") + " %i",
+	})
+}
+
+// CreateUnit creates or rewrites the unit running `manygram run --wait PROFILE`
+func CreateUnit(dir string, profile string, description string, execPath string) error {
+	if description == "" {
+		description = "Telegram Desktop (manygram profile " + profile + ")"
+	}
+	return write(UnitPath(dir, profile), &unitValues{
+		Description: escapeSpecifiers(description),
+		ExecStart:   ExecLine(execPath, "run", "--wait", profile),
+	})
+}
+
+// Exist checks whether the unit running the profile exists
+func Exist(dir, profile string) (bool, error) {
+	return util.Exist(UnitPath(dir, profile))
+}
+
+// Remove removes the unit running the profile
+func Remove(dir, profile string) error {
+	return os.Remove(UnitPath(dir, profile))
+}
+
+var specifierReplacer = strings.NewReplacer("%", "%%", "\n", " ")
+
+func escapeSpecifiers(value string) string {
+	return specifierReplacer.Replace(value)
+}
+
+var quoteReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$", "\n", `\n`)
+
+// ExecLine builds the command line of the ExecStart setting quoting arguments if needed
+// and escaping specifiers and variables
+func ExecLine(args ...string) string {
+	quoted := make([]string, len(args))
+	for idx, arg := range args {
+		escaped := quoteReplacer.Replace(arg)
+		if arg == "" || escaped != arg || strings.ContainsAny(arg, " \t'") {
+			escaped = `"` + escaped + `"`
+		}
+		quoted[idx] = escaped
+	}
+	return strings.Join(quoted, " ")
+}
+
+func write(path string, values *unitValues) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return unitTemplate.Execute(file, values)
+}
@@ -0,0 +1,77 @@
+package systemd
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSystemdSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *TestSystemdSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "test-systemd-*")
+	s.Require().NoError(err)
+	s.dir = path.Join(dir, "systemd", "user")
+}
+
+func (s *TestSystemdSuite) TearDownTest() {
+	err := os.RemoveAll(path.Dir(path.Dir(s.dir)))
+	s.Require().NoError(err)
+}
+
+func (s *TestSystemdSuite) Read(name string) string {
+	content, err := ioutil.ReadFile(path.Join(s.dir, name))
+	s.Require().NoError(err)
+	return string(content)
+}
+
+func (s *TestSystemdSuite) TestCreateTemplate() {
+	err := CreateTemplate(s.dir, "/usr/bin/manygram")
+	s.Require().NoError(err)
+	content := s.Read("manygram@.service")
+	s.Require().Contains(content, "\nDescription=Telegram Desktop (manygram profile %i)\n")
+	s.Require().Contains(content, "\nExecStart=/usr/bin/manygram run --wait %i\n")
+	s.Require().Contains(content, "\nRestart=on-failure\n")
+	s.Require().Contains(content, "\nWantedBy=graphical-session.target\n")
+}
+
+func (s *TestSystemdSuite) TestCreateUnit() {
+	err := CreateUnit(s.dir, "foo", "", "/home/user/my apps/manygram")
+	s.Require().NoError(err)
+	content := s.Read("manygram-foo.service")
+	s.Require().Contains(content, "\nDescription=Telegram Desktop (manygram profile foo)\n")
+	s.Require().Contains(content, "\nExecStart=\"/home/user/my apps/manygram\" run --wait foo\n")
+	exist, err := Exist(s.dir, "foo")
+	s.Require().NoError(err)
+	s.Require().True(exist)
+	s.Require().NoError(Remove(s.dir, "foo"))
+	s.Require().NoFileExists(UnitPath(s.dir, "foo"))
+	exist, err = Exist(s.dir, "foo")
+	s.Require().NoError(err)
+	s.Require().False(exist)
+}
+
+func (s *TestSystemdSuite) TestCreateUnitDescription() {
+	err := CreateUnit(s.dir, "foo", "Support 100%", "/usr/bin/manygram")
+	s.Require().NoError(err)
+	s.Require().Contains(s.Read("manygram-foo.service"), "\nDescription=Support 100%%\n")
+}
+
+func (s *TestSystemdSuite) TestExecLine() {
+	s.Require().Equal(`/bin/a "b c" "" "100%%" "$$HOME" "\"\\"`, ExecLine("/bin/a", "b c", "", "100%", "$HOME", `"\`))
+}
+
+func (s *TestSystemdSuite) TestNames() {
+	s.Require().Equal("manygram@foo.service", InstanceName("foo"))
+	s.Require().Equal("manygram-foo.service", UnitName("foo"))
+}
+
+func TestSystemdSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestSystemdSuite))
+}
@@ -2,10 +2,13 @@ package tg
 
 import (
 	"errors"
+	"io"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 // DefaultPath is the default path/name of Telegram Desktop executable
@@ -32,19 +35,122 @@ func Executable(path string, args []string) (*TelegramDesktop, error) {
 	return &TelegramDesktop{path, fullPath, realPath, args}, nil
 }
 
-// Run executes telegram-desktop executable
-func (tg *TelegramDesktop) Run(profilePath string, extraArgs []string, wait bool) error {
-	args := make([]string, len(tg.Args)+len(extraArgs)+3)
-	copy(args, tg.Args)
+// Options represents optional parameters of the Telegram Desktop process
+type Options struct {
+	// Env variables are added to the environment inherited from the current process
+	Env map[string]string
+	// UnsetEnv variables are removed from the environment inherited from the current process
+	UnsetEnv []string
+	// WMClass is the X11 WM_CLASS instance name of the windows (set with Qt -name option
+	// and RESOURCE_NAME variable), the default one is used if empty
+	WMClass string
+	// Stdout and Stderr of the process, the output is discarded if nil
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// withEnv returns a copy of the options with the variable added to Env
+func (opts *Options) withEnv(key string, value string) *Options {
+	copied := *opts
+	copied.Env = make(map[string]string, len(opts.Env)+1)
+	for k, v := range opts.Env {
+		copied.Env[k] = v
+	}
+	copied.Env[key] = value
+	return &copied
+}
+
+func (opts *Options) envKeys() []string {
+	keys := make([]string, 0, len(opts.Env))
+	for key := range opts.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// environ returns the environment of the child process or nil if it is inherited unchanged
+func (opts *Options) environ() []string {
+	if len(opts.Env) == 0 && len(opts.UnsetEnv) == 0 {
+		return nil
+	}
+	removed := make(map[string]bool, len(opts.Env)+len(opts.UnsetEnv))
+	for _, key := range opts.UnsetEnv {
+		removed[key] = true
+	}
+	for key := range opts.Env {
+		removed[key] = true
+	}
+	var env []string
+	for _, item := range os.Environ() {
+		if !removed[strings.SplitN(item, "=", 2)[0]] {
+			env = append(env, item)
+		}
+	}
+	for _, key := range opts.envKeys() {
+		env = append(env, key+"="+opts.Env[key])
+	}
+	return env
+}
+
+// flatpakArgs returns `flatpak run` options passing the environment into the sandbox
+func (opts *Options) flatpakArgs() []string {
+	args := make([]string, 0, len(opts.Env)+len(opts.UnsetEnv))
+	for _, key := range opts.envKeys() {
+		args = append(args, "--env="+key+"="+opts.Env[key])
+	}
+	for _, key := range opts.UnsetEnv {
+		args = append(args, "--unset-env="+key)
+	}
+	return args
+}
+
+// Start starts telegram-desktop executable and returns the started command
+func (tg *TelegramDesktop) Start(profilePath string, extraArgs []string, opts *Options) (*exec.Cmd, error) {
+	if opts == nil {
+		opts = new(Options)
+	}
+	if opts.WMClass != "" {
+		opts = opts.withEnv("RESOURCE_NAME", opts.WMClass)
+	}
+	args := make([]string, 0, len(tg.Args)+len(extraArgs)+len(opts.Env)+len(opts.UnsetEnv)+5)
+	isFlatpak := tg.IsFlatpak()
+	if isFlatpak {
+		// the environment of the flatpak process is not passed into the sandbox
+		args = append(args, tg.Args[0])
+		args = append(args, opts.flatpakArgs()...)
+		args = append(args, tg.Args[1:]...)
+	} else {
+		args = append(args, tg.Args...)
+	}
+	if opts.WMClass != "" {
+		args = append(args, "-name", opts.WMClass)
+	}
 	args = append(args, "-many", "-workdir", profilePath)
 	args = append(args, extraArgs...)
 	cmd := exec.Command(tg.Path, args...)
-	if wait {
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		return cmd.Run()
+	if !isFlatpak {
+		cmd.Env = opts.environ()
+	}
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// Run executes telegram-desktop executable
+func (tg *TelegramDesktop) Run(profilePath string, extraArgs []string, wait bool) error {
+	if !wait {
+		_, err := tg.Start(profilePath, extraArgs, nil)
+		return err
 	}
-	return cmd.Start()
+	cmd, err := tg.Start(profilePath, extraArgs, &Options{Stdout: os.Stdout, Stderr: os.Stderr})
+	if err != nil {
+		return err
+	}
+	return cmd.Wait()
 }
 
 // IsSnap returns true if executable seems installed with snap
@@ -52,6 +158,22 @@ func (tg *TelegramDesktop) IsSnap() bool {
 	return path.Base(tg.RealPath) == "snap"
 }
 
+// Flavor returns the installation type of Telegram Desktop: "flatpak", "snap", or "native"
+func (tg *TelegramDesktop) Flavor() string {
+	if tg.IsFlatpak() {
+		return "flatpak"
+	}
+	if tg.IsSnap() {
+		return "snap"
+	}
+	return "native"
+}
+
+// IsFlatpak returns true if executable is Flatpak app runner
+func (tg *TelegramDesktop) IsFlatpak() bool {
+	return path.Base(tg.RealPath) == flatpakExecName && len(tg.Args) > 0 && tg.Args[0] == "run"
+}
+
 // GetSnapDataHome returns XDG_DATA_HOME of Telegram Desktop snap or error
 func GetSnapDataHome() (string, error) {
 	snapDataHome := os.ExpandEnv("$HOME/snap/telegram-desktop/current/.local/share")
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -137,6 +138,85 @@ func (s *TestExecutableSuite) TestOKWithArgs() {
 	}, tg)
 }
 
+func (s *TestExecutableSuite) TestStart() {
+	f, err := os.OpenFile(s.execPath, os.O_CREATE|os.O_WRONLY, 0777)
+	s.Require().NoError(err)
+	_, err = f.WriteString("#!/bin/sh\nfor arg; do echo \"$arg\"; done\n")
+	s.Require().NoError(err)
+	f.Close()
+	tg, err := Executable(s.execPath, []string{"-extra"})
+	s.Require().NoError(err)
+	out := new(strings.Builder)
+	cmd, err := tg.Start("/path/to/profile", []string{"--", "tg://resolve"}, &Options{Stdout: out})
+	s.Require().NoError(err)
+	s.Require().NoError(cmd.Wait())
+	s.Require().Equal("-extra\n-many\n-workdir\n/path/to/profile\n--\ntg://resolve\n", out.String())
+}
+
+func (s *TestExecutableSuite) TestStartEnv() {
+	f, err := os.OpenFile(s.execPath, os.O_CREATE|os.O_WRONLY, 0777)
+	s.Require().NoError(err)
+	_, err = f.WriteString("#!/bin/sh\necho \"$MANYGRAM_TEST_FOO $MANYGRAM_TEST_BAR ${MANYGRAM_TEST_UNSET-unset}\"\n")
+	s.Require().NoError(err)
+	f.Close()
+	os.Setenv("MANYGRAM_TEST_FOO", "orig")
+	os.Setenv("MANYGRAM_TEST_UNSET", "orig")
+	defer os.Unsetenv("MANYGRAM_TEST_FOO")
+	defer os.Unsetenv("MANYGRAM_TEST_UNSET")
+	tg, err := Executable(s.execPath, nil)
+	s.Require().NoError(err)
+	out := new(strings.Builder)
+	cmd, err := tg.Start("/path/to/profile", nil, &Options{
+		Env:      map[string]string{"MANYGRAM_TEST_FOO": "foo", "MANYGRAM_TEST_BAR": "bar baz"},
+		UnsetEnv: []string{"MANYGRAM_TEST_UNSET"},
+		Stdout:   out,
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(cmd.Wait())
+	s.Require().Equal("foo bar baz unset\n", out.String())
+}
+
+func (s *TestExecutableSuite) TestStartFlatpakEnv() {
+	flatpakPath := path.Join(s.dir, "flatpak")
+	f, err := os.OpenFile(flatpakPath, os.O_CREATE|os.O_WRONLY, 0777)
+	s.Require().NoError(err)
+	_, err = f.WriteString("#!/bin/sh\necho \"$@ ${MANYGRAM_TEST_FOO-unset}\"\n")
+	s.Require().NoError(err)
+	f.Close()
+	tg, err := Executable(flatpakPath, []string{"run", "org.telegram.desktop"})
+	s.Require().NoError(err)
+	out := new(strings.Builder)
+	cmd, err := tg.Start("/profile", nil, &Options{
+		Env:      map[string]string{"MANYGRAM_TEST_FOO": "foo", "QT_QPA_PLATFORM": "xcb"},
+		UnsetEnv: []string{"http_proxy"},
+		Stdout:   out,
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(cmd.Wait())
+	s.Require().Equal(
+		"run --env=MANYGRAM_TEST_FOO=foo --env=QT_QPA_PLATFORM=xcb --unset-env=http_proxy "+
+			"org.telegram.desktop -many -workdir /profile unset\n",
+		out.String(),
+	)
+}
+
+func (s *TestExecutableSuite) TestStartWMClass() {
+	f, err := os.OpenFile(s.execPath, os.O_CREATE|os.O_WRONLY, 0777)
+	s.Require().NoError(err)
+	_, err = f.WriteString("#!/bin/sh\necho \"$@ $RESOURCE_NAME\"\n")
+	s.Require().NoError(err)
+	f.Close()
+	tg, err := Executable(s.execPath, nil)
+	s.Require().NoError(err)
+	out := new(strings.Builder)
+	opts := &Options{WMClass: "manygram-foo", Stdout: out}
+	cmd, err := tg.Start("/profile", nil, opts)
+	s.Require().NoError(err)
+	s.Require().NoError(cmd.Wait())
+	s.Require().Equal("-name manygram-foo -many -workdir /profile manygram-foo\n", out.String())
+	s.Require().Nil(opts.Env)
+}
+
 func (s *TestExecutableSuite) TestIsSnapFalse() {
 	s.CreateFile(s.execPath, true)
 	symlinkPath := path.Join(s.dir, symlinkName)
@@ -156,6 +236,25 @@ func (s *TestExecutableSuite) TestIsSnapTrue() {
 	s.Require().True(tg.IsSnap())
 }
 
+func (s *TestExecutableSuite) TestFlavor() {
+	flatpakPath := path.Join(s.dir, "flatpak")
+	s.CreateFile(flatpakPath, true)
+	tg, err := Executable(flatpakPath, []string{"run", "org.telegram.desktop"})
+	s.Require().NoError(err)
+	s.Require().True(tg.IsFlatpak())
+	s.Require().Equal("flatpak", tg.Flavor())
+	snapPath := path.Join(s.dir, "snap")
+	s.CreateFile(snapPath, true)
+	tg, err = Executable(snapPath, nil)
+	s.Require().NoError(err)
+	s.Require().False(tg.IsFlatpak())
+	s.Require().Equal("snap", tg.Flavor())
+	s.CreateFile(s.execPath, true)
+	tg, err = Executable(s.execPath, nil)
+	s.Require().NoError(err)
+	s.Require().Equal("native", tg.Flavor())
+}
+
 func TestExecutableSuiteTest(t *testing.T) {
 	suite.Run(t, new(TestExecutableSuite))
 }
@@ -0,0 +1,76 @@
+package tg
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ErrUnsupportedURL is returned by the NormalizeURL() function if the URL cannot be opened by Telegram Desktop
+var ErrUnsupportedURL = errors.New("unsupported URL")
+
+// webHosts are the hosts of web links converted into tg:// links
+var webHosts = map[string]bool{
+	"t.me":            true,
+	"www.t.me":        true,
+	"telegram.me":     true,
+	"www.telegram.me": true,
+	"telegram.dog":    true,
+}
+
+var phoneRegexp = regexp.MustCompile("^[0-9]+$")
+
+// NormalizeURL returns tg:// link passed to Telegram Desktop, https://t.me/ links
+// of users, channels, posts and invites are converted into corresponding tg:// links
+func NormalizeURL(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", ErrUnsupportedURL
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "tg":
+		return u.String(), nil
+	case "http", "https":
+		if !webHosts[strings.ToLower(u.Host)] {
+			return "", ErrUnsupportedURL
+		}
+	default:
+		return "", ErrUnsupportedURL
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if parts[0] == "" || parts[0] == "s" && len(parts) > 1 {
+		// t.me/s/channel is the web preview of the channel
+		parts = parts[1:]
+	}
+	if len(parts) == 0 {
+		return "", ErrUnsupportedURL
+	}
+	query := url.Values{}
+	switch {
+	case parts[0] == "joinchat" && len(parts) == 2:
+		query.Set("invite", parts[1])
+		return buildURL("join", query), nil
+	case strings.HasPrefix(parts[0], "+") && len(parts) == 1:
+		if phoneRegexp.MatchString(parts[0][1:]) {
+			query.Set("phone", parts[0][1:])
+			return buildURL("resolve", query), nil
+		}
+		query.Set("invite", parts[0][1:])
+		return buildURL("join", query), nil
+	case len(parts) > 2:
+		return "", ErrUnsupportedURL
+	}
+	for key, values := range u.Query() {
+		query[key] = values
+	}
+	query.Set("domain", parts[0])
+	if len(parts) == 2 {
+		query.Set("post", parts[1])
+	}
+	return buildURL("resolve", query), nil
+}
+
+func buildURL(host string, query url.Values) string {
+	return (&url.URL{Scheme: "tg", Host: host, RawQuery: query.Encode()}).String()
+}
@@ -0,0 +1,53 @@
+package tg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestNormalizeURLSuite struct {
+	suite.Suite
+}
+
+func (s *TestNormalizeURLSuite) TestTgURL() {
+	url, err := NormalizeURL(" tg://resolve?domain=durov ")
+	s.Require().NoError(err)
+	s.Require().Equal("tg://resolve?domain=durov", url)
+}
+
+func (s *TestNormalizeURLSuite) TestWebURL() {
+	for rawURL, expected := range map[string]string{
+		"https://t.me/durov":                 "tg://resolve?domain=durov",
+		"http://www.telegram.me/durov/":      "tg://resolve?domain=durov",
+		"https://t.me/telegram/123":          "tg://resolve?domain=telegram&post=123",
+		"https://t.me/s/telegram":            "tg://resolve?domain=telegram",
+		"https://t.me/mybot?start=abc":       "tg://resolve?domain=mybot&start=abc",
+		"https://t.me/joinchat/AAAAAEHbEkej": "tg://join?invite=AAAAAEHbEkej",
+		"https://t.me/+AAAAAEHbEkej":         "tg://join?invite=AAAAAEHbEkej",
+		"https://t.me/+79991234567":          "tg://resolve?phone=79991234567",
+	} {
+		url, err := NormalizeURL(rawURL)
+		s.Require().NoError(err, rawURL)
+		s.Require().Equal(expected, url, rawURL)
+	}
+}
+
+func (s *TestNormalizeURLSuite) TestErrUnsupportedURL() {
+	for _, rawURL := range []string{
+		"https://example.com/durov",
+		"https://t.me/",
+		"https://t.me/a/b/c",
+		"mailto:durov@t.me",
+		"durov",
+		"%zz",
+	} {
+		_, err := NormalizeURL(rawURL)
+		s.Require().True(errors.Is(err, ErrUnsupportedURL), rawURL)
+	}
+}
+
+func TestNormalizeURLSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestNormalizeURLSuite))
+}
@@ -0,0 +1,290 @@
+package trash
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrNotInTrash is returned by the Find() function when there is no trashed item with the original path
+var ErrNotInTrash = errors.New("not in trash")
+
+const infoSuffix = ".trashinfo"
+const dateLayout = "2006-01-02T15:04:05"
+
+// Item is a trashed file or directory
+type Item struct {
+	// Name is the name of the item in the files directory of the trash
+	Name string
+	// Path is the original absolute path of the item
+	Path         string
+	DeletionDate time.Time
+}
+
+// Dir returns the trash directory for the file: the home trash if the file is on the same filesystem,
+// otherwise $topdir/.Trash/$uid if $topdir/.Trash is a sticky directory or $topdir/.Trash-$uid,
+// where $topdir is the mount point of the filesystem; the file does not have to exist
+func Dir(homeTrash string, filePath string) (string, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", err
+	}
+	existing, dev, err := nearestExisting(absPath)
+	if err != nil {
+		return "", err
+	}
+	_, homeDev, err := nearestExisting(homeTrash)
+	if err != nil {
+		return "", err
+	}
+	if dev == homeDev {
+		return homeTrash, nil
+	}
+	topdir := existing
+	for topdir != "/" {
+		parent := path.Dir(topdir)
+		_, parentDev, err := nearestExisting(parent)
+		if err != nil {
+			return "", err
+		}
+		if parentDev != dev {
+			break
+		}
+		topdir = parent
+	}
+	return topdirTrash(topdir), nil
+}
+
+// topdirTrash returns the trash directory of the filesystem mounted at topdir
+func topdirTrash(topdir string) string {
+	uid := fmt.Sprint(os.Getuid())
+	shared := path.Join(topdir, ".Trash")
+	// the shared trash must not be a symlink and must have the sticky bit set
+	if info, err := os.Lstat(shared); err == nil && info.IsDir() && info.Mode()&os.ModeSticky != 0 {
+		return path.Join(shared, uid)
+	}
+	return path.Join(topdir, ".Trash-"+uid)
+}
+
+// topdirOf returns the top directory of the $topdir trash or an empty string for the home trash
+func topdirOf(trashDir string) string {
+	if strings.HasPrefix(path.Base(trashDir), ".Trash-") {
+		return path.Dir(trashDir)
+	}
+	if path.Base(path.Dir(trashDir)) == ".Trash" {
+		return path.Dir(path.Dir(trashDir))
+	}
+	return ""
+}
+
+// nearestExisting returns the path and the device of the file or its nearest existing parent
+func nearestExisting(filePath string) (string, uint64, error) {
+	for {
+		info, err := os.Stat(filePath)
+		if err == nil {
+			stat, ok := info.Sys().(*syscall.Stat_t)
+			if !ok {
+				return "", 0, fmt.Errorf("%s: unsupported file info", filePath)
+			}
+			return filePath, uint64(stat.Dev), nil
+		}
+		if !os.IsNotExist(err) || filePath == "/" || filePath == "." {
+			return "", 0, err
+		}
+		filePath = path.Dir(filePath)
+	}
+}
+
+// FilesPath returns the path to the trashed file or directory
+func FilesPath(trashDir string, name string) string {
+	return path.Join(trashDir, "files", name)
+}
+
+// InfoPath returns the path to the .trashinfo file of the trashed file or directory
+func InfoPath(trashDir string, name string) string {
+	return path.Join(trashDir, "info", name+infoSuffix)
+}
+
+// Put moves the file or directory to the trash according to the freedesktop.org Trash specification,
+// the trash must be on the same filesystem as the file, see Dir()
+func Put(trashDir string, filePath string) (*Item, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Lstat(absPath); err != nil {
+		return nil, err
+	}
+	for _, dir := range []string{path.Join(trashDir, "files"), path.Join(trashDir, "info")} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+	}
+	item := &Item{Path: absPath, DeletionDate: time.Now()}
+	base := path.Base(absPath)
+	for idx := 1; ; idx++ {
+		item.Name = base
+		if idx > 1 {
+			item.Name = fmt.Sprintf("%s.%d", base, idx)
+		}
+		// the info file is created exclusively first to reserve the name
+		infoFile, err := os.OpenFile(InfoPath(trashDir, item.Name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Lstat(FilesPath(trashDir, item.Name)); err == nil {
+			infoFile.Close()
+			os.Remove(infoFile.Name())
+			continue
+		}
+		_, err = infoFile.WriteString(item.info(topdirOf(trashDir)))
+		if closeErr := infoFile.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(absPath, FilesPath(trashDir, item.Name))
+		}
+		if err != nil {
+			os.Remove(infoFile.Name())
+			return nil, err
+		}
+		return item, nil
+	}
+}
+
+// info returns the content of the .trashinfo file, the path is relative to the top directory of the $topdir trash
+func (i *Item) info(topdir string) string {
+	itemPath := i.Path
+	if topdir != "" {
+		if relPath, err := filepath.Rel(topdir, itemPath); err == nil && !strings.HasPrefix(relPath, "..") {
+			itemPath = relPath
+		}
+	}
+	escapedPath := (&url.URL{Path: itemPath}).EscapedPath()
+	return fmt.Sprintf(
+		"[Trash Info]\nPath=%s\nDeletionDate=%s\n", escapedPath, i.DeletionDate.Format(dateLayout),
+	)
+}
+
+// List returns items found in the trash, items with malformed or missing .trashinfo files are skipped
+func List(trashDir string) ([]*Item, error) {
+	infos, err := ioutil.ReadDir(path.Join(trashDir, "info"))
+	if err != nil {
+		return nil, err
+	}
+	var items []*Item
+	for _, info := range infos {
+		name := strings.TrimSuffix(info.Name(), infoSuffix)
+		if name == info.Name() || !info.Mode().IsRegular() {
+			continue
+		}
+		item, err := readInfo(trashDir, name)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Lstat(FilesPath(trashDir, name)); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func readInfo(trashDir string, name string) (*Item, error) {
+	file, err := os.Open(InfoPath(trashDir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	item := &Item{Name: name}
+	scanner := bufio.NewScanner(file)
+	inGroup := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inGroup = line == "[Trash Info]"
+			continue
+		}
+		if !inGroup {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		switch strings.TrimSpace(parts[0]) {
+		case "Path":
+			if item.Path, err = url.PathUnescape(strings.TrimSpace(parts[1])); err != nil {
+				return nil, err
+			}
+		case "DeletionDate":
+			item.DeletionDate, _ = time.ParseInLocation(dateLayout, strings.TrimSpace(parts[1]), time.Local)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if item.Path == "" {
+		return nil, fmt.Errorf("%s: no Path key", file.Name())
+	}
+	if !path.IsAbs(item.Path) {
+		topdir := topdirOf(trashDir)
+		if topdir == "" {
+			return nil, fmt.Errorf("%s: relative Path key", file.Name())
+		}
+		item.Path = path.Join(topdir, item.Path)
+	}
+	return item, nil
+}
+
+// Find returns the most recently trashed item with the original path
+func Find(trashDir string, filePath string) (*Item, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+	items, err := List(trashDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var found *Item
+	for _, item := range items {
+		if item.Path != absPath {
+			continue
+		}
+		if found == nil || !item.DeletionDate.Before(found.DeletionDate) {
+			found = item
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%s: %w", absPath, ErrNotInTrash)
+	}
+	return found, nil
+}
+
+// Restore moves the trashed item back to its original path unless the path exists
+func Restore(trashDir string, item *Item) error {
+	if _, err := os.Lstat(item.Path); err == nil {
+		return fmt.Errorf("%s: %w", item.Path, os.ErrExist)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(path.Dir(item.Path), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(FilesPath(trashDir, item.Name), item.Path); err != nil {
+		return err
+	}
+	return os.Remove(InfoPath(trashDir, item.Name))
+}
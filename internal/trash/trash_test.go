@@ -0,0 +1,162 @@
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestTrashSuite struct {
+	suite.Suite
+	dir      string
+	trashDir string
+}
+
+func (s *TestTrashSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "test-trash-*")
+	s.Require().NoError(err)
+	s.dir = dir
+	s.trashDir = path.Join(dir, "Trash")
+}
+
+func (s *TestTrashSuite) TearDownTest() {
+	s.Require().NoError(os.RemoveAll(s.dir))
+}
+
+func (s *TestTrashSuite) CreateDir(name string, content string) string {
+	dirPath := path.Join(s.dir, name)
+	s.Require().NoError(os.MkdirAll(dirPath, 0755))
+	s.Require().NoError(ioutil.WriteFile(path.Join(dirPath, "data"), []byte(content), 0644))
+	return dirPath
+}
+
+func (s *TestTrashSuite) TestPut() {
+	dirPath := s.CreateDir("my profile", "foo")
+	item, err := Put(s.trashDir, dirPath)
+	s.Require().NoError(err)
+	s.Require().Equal("my profile", item.Name)
+	s.Require().Equal(dirPath, item.Path)
+	s.Require().NoDirExists(dirPath)
+	s.Require().FileExists(path.Join(s.trashDir, "files", "my profile", "data"))
+	info, err := ioutil.ReadFile(path.Join(s.trashDir, "info", "my profile.trashinfo"))
+	s.Require().NoError(err)
+	s.Require().Regexp(
+		"^\\[Trash Info\\]\nPath="+path.Join(s.dir, "my%20profile")+"\nDeletionDate=\\d{4}-\\d\\d-\\d\\dT\\d\\d:\\d\\d:\\d\\d\n$",
+		string(info),
+	)
+}
+
+func (s *TestTrashSuite) TestPutErrNotExist() {
+	_, err := Put(s.trashDir, path.Join(s.dir, "missing"))
+	s.Require().True(errors.Is(err, os.ErrNotExist), err)
+}
+
+func (s *TestTrashSuite) TestPutSameName() {
+	first, err := Put(s.trashDir, s.CreateDir("foo", "first"))
+	s.Require().NoError(err)
+	second, err := Put(s.trashDir, s.CreateDir("foo", "second"))
+	s.Require().NoError(err)
+	s.Require().Equal("foo", first.Name)
+	s.Require().Equal("foo.2", second.Name)
+	items, err := List(s.trashDir)
+	s.Require().NoError(err)
+	s.Require().Len(items, 2)
+}
+
+func (s *TestTrashSuite) TestFindMostRecent() {
+	_, err := Put(s.trashDir, s.CreateDir("foo", "first"))
+	s.Require().NoError(err)
+	_, err = Put(s.trashDir, s.CreateDir("foo", "second"))
+	s.Require().NoError(err)
+	info := "[Trash Info]\nPath=" + path.Join(s.dir, "foo") + "\nDeletionDate=2000-01-01T00:00:00\n"
+	s.Require().NoError(ioutil.WriteFile(InfoPath(s.trashDir, "foo.2"), []byte(info), 0600))
+	found, err := Find(s.trashDir, path.Join(s.dir, "foo"))
+	s.Require().NoError(err)
+	s.Require().Equal("foo", found.Name)
+}
+
+func (s *TestTrashSuite) TestList() {
+	_, err := Put(s.trashDir, s.CreateDir("foo", "foo"))
+	s.Require().NoError(err)
+	// items without the file and malformed info files are skipped
+	infoDir := path.Join(s.trashDir, "info")
+	s.Require().NoError(ioutil.WriteFile(path.Join(infoDir, "orphan.trashinfo"), []byte("[Trash Info]\nPath=/orphan\n"), 0600))
+	s.Require().NoError(os.Mkdir(path.Join(s.trashDir, "files", "bad"), 0700))
+	s.Require().NoError(ioutil.WriteFile(path.Join(infoDir, "bad.trashinfo"), []byte("[Other]\nPath=/bad\n"), 0600))
+	items, err := List(s.trashDir)
+	s.Require().NoError(err)
+	s.Require().Len(items, 1)
+	s.Require().Equal(path.Join(s.dir, "foo"), items[0].Path)
+	s.Require().False(items[0].DeletionDate.IsZero())
+}
+
+func (s *TestTrashSuite) TestFindErrNotInTrash() {
+	_, err := Find(s.trashDir, path.Join(s.dir, "foo"))
+	s.Require().True(errors.Is(err, ErrNotInTrash), err)
+}
+
+func (s *TestTrashSuite) TestRestore() {
+	dirPath := s.CreateDir("foo", "foo")
+	item, err := Put(s.trashDir, dirPath)
+	s.Require().NoError(err)
+	s.Require().NoError(Restore(s.trashDir, item))
+	s.Require().FileExists(path.Join(dirPath, "data"))
+	s.Require().NoFileExists(InfoPath(s.trashDir, item.Name))
+	_, err = Find(s.trashDir, dirPath)
+	s.Require().True(errors.Is(err, ErrNotInTrash), err)
+}
+
+func (s *TestTrashSuite) TestRestoreErrExist() {
+	dirPath := s.CreateDir("foo", "foo")
+	item, err := Put(s.trashDir, dirPath)
+	s.Require().NoError(err)
+	s.CreateDir("foo", "new")
+	err = Restore(s.trashDir, item)
+	s.Require().True(errors.Is(err, os.ErrExist), err)
+	s.Require().DirExists(FilesPath(s.trashDir, item.Name))
+}
+
+func (s *TestTrashSuite) TestDirSameFilesystem() {
+	trashDir, err := Dir(s.trashDir, path.Join(s.dir, "missing", "foo"))
+	s.Require().NoError(err)
+	s.Require().Equal(s.trashDir, trashDir)
+}
+
+func (s *TestTrashSuite) TestDirOtherFilesystem() {
+	// procfs is never the filesystem of the temporary directory
+	trashDir, err := Dir(s.trashDir, "/proc/self")
+	s.Require().NoError(err)
+	s.Require().Equal(fmt.Sprintf("/proc/.Trash-%d", os.Getuid()), trashDir)
+}
+
+func (s *TestTrashSuite) TestTopdirTrash() {
+	uid := fmt.Sprint(os.Getuid())
+	s.Require().Equal(path.Join(s.dir, ".Trash-"+uid), topdirTrash(s.dir))
+	shared := path.Join(s.dir, ".Trash")
+	s.Require().NoError(os.Mkdir(shared, 0777))
+	s.Require().Equal(path.Join(s.dir, ".Trash-"+uid), topdirTrash(s.dir))
+	s.Require().NoError(os.Chmod(shared, 0777|os.ModeSticky))
+	s.Require().Equal(path.Join(shared, uid), topdirTrash(s.dir))
+}
+
+func (s *TestTrashSuite) TestPutTopdirTrash() {
+	trashDir := path.Join(s.dir, fmt.Sprintf(".Trash-%d", os.Getuid()))
+	dirPath := s.CreateDir("foo", "foo")
+	_, err := Put(trashDir, dirPath)
+	s.Require().NoError(err)
+	info, err := ioutil.ReadFile(InfoPath(trashDir, "foo"))
+	s.Require().NoError(err)
+	s.Require().Contains(string(info), "\nPath=foo\n")
+	found, err := Find(trashDir, dirPath)
+	s.Require().NoError(err)
+	s.Require().Equal(dirPath, found.Path)
+}
+
+func TestTrashSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestTrashSuite))
+}
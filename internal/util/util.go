@@ -2,7 +2,9 @@ package util
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"syscall"
 )
 
 // Exist checks whether the specified path exists
@@ -16,3 +18,31 @@ func Exist(path string) (bool, error) {
 		return false, err
 	}
 }
+
+// ErrNotPrivate is returned by the MkdirPrivate() function if the directory may be accessed by other users
+var ErrNotPrivate = errors.New("directory is not private")
+
+// MkdirPrivate creates the directory with 0700 mode if it does not exist, the existing directory
+// is only accepted if it is not a symlink, is owned by the current user and has 0700 mode
+func MkdirPrivate(path string) error {
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return err
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("%w: %s is a symlink", ErrNotPrivate, path)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%w: %s is not a directory", ErrNotPrivate, path)
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("%w: %s is owned by another user", ErrNotPrivate, path)
+	}
+	if info.Mode().Perm() != 0700 {
+		return fmt.Errorf("%w: %s has mode %#o instead of 0700", ErrNotPrivate, path, info.Mode().Perm())
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"errors"
 	"io/ioutil"
 	"os"
 	"path"
@@ -53,3 +54,52 @@ func (s *TestExistSuite) TestExistFile() {
 func TestExistSuiteTest(t *testing.T) {
 	suite.Run(t, new(TestExistSuite))
 }
+
+type TestMkdirPrivateSuite struct {
+	suite.Suite
+	dir  string
+	path string
+}
+
+func (s *TestMkdirPrivateSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "test-util-mkdir-*")
+	s.Require().NoError(err)
+	s.dir = dir
+	s.path = path.Join(dir, "path")
+}
+
+func (s *TestMkdirPrivateSuite) TearDownTest() {
+	err := os.RemoveAll(s.dir)
+	s.Require().NoError(err)
+}
+
+func (s *TestMkdirPrivateSuite) TestCreate() {
+	s.Require().NoError(MkdirPrivate(s.path))
+	info, err := os.Stat(s.path)
+	s.Require().NoError(err)
+	s.Require().True(info.IsDir())
+	s.Require().Equal(os.FileMode(0700), info.Mode().Perm())
+	s.Require().NoError(MkdirPrivate(s.path))
+}
+
+func (s *TestMkdirPrivateSuite) TestSymlink() {
+	target := path.Join(s.dir, "target")
+	s.Require().NoError(os.Mkdir(target, 0700))
+	s.Require().NoError(os.Symlink(target, s.path))
+	s.Require().True(errors.Is(MkdirPrivate(s.path), ErrNotPrivate))
+}
+
+func (s *TestMkdirPrivateSuite) TestMode() {
+	s.Require().NoError(os.Mkdir(s.path, 0700))
+	s.Require().NoError(os.Chmod(s.path, 0755))
+	s.Require().True(errors.Is(MkdirPrivate(s.path), ErrNotPrivate))
+}
+
+func (s *TestMkdirPrivateSuite) TestFile() {
+	s.Require().NoError(ioutil.WriteFile(s.path, nil, 0600))
+	s.Require().Error(MkdirPrivate(s.path))
+}
+
+func TestMkdirPrivateSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestMkdirPrivateSuite))
+}
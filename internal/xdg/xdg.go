@@ -22,3 +22,18 @@ func GetConfigHome() string {
 func GetDataHome() string {
 	return getXDGDirectory("XDG_DATA_HOME", "$HOME/.local/share")
 }
+
+// GetStateHome returns the path of $XDG_STATE_HOME directory
+func GetStateHome() string {
+	return getXDGDirectory("XDG_STATE_HOME", "$HOME/.local/state")
+}
+
+// GetRuntimeDir returns the path of $XDG_RUNTIME_DIR directory,
+// the temporary directory is used if the variable is not set
+func GetRuntimeDir() string {
+	fromEnv := os.Getenv("XDG_RUNTIME_DIR")
+	if fromEnv != "" && filepath.IsAbs(fromEnv) {
+		return fromEnv
+	}
+	return os.TempDir()
+}
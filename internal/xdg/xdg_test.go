@@ -88,3 +88,31 @@ func (s *TestGetDataHomeSuite) SetupSuite() {
 func TestGetConfigDataSuiteTest(t *testing.T) {
 	suite.Run(t, new(TestGetDataHomeSuite))
 }
+
+type TestGetStateHomeSuite struct {
+	BaseSuite
+}
+
+func (s *TestGetStateHomeSuite) SetupSuite() {
+	s.function = GetStateHome
+	s.varName = "XDG_STATE_HOME"
+	s.defaultValue = os.ExpandEnv("$HOME/.local/state")
+}
+
+func TestGetStateHomeSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestGetStateHomeSuite))
+}
+
+type TestGetRuntimeDirSuite struct {
+	BaseSuite
+}
+
+func (s *TestGetRuntimeDirSuite) SetupSuite() {
+	s.function = GetRuntimeDir
+	s.varName = "XDG_RUNTIME_DIR"
+	s.defaultValue = os.TempDir()
+}
+
+func TestGetRuntimeDirSuiteTest(t *testing.T) {
+	suite.Run(t, new(TestGetRuntimeDirSuite))
+}